@@ -1,18 +1,43 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/matteo-gildone/teamtime/internals/geo"
 	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/matteo-gildone/teamtime/internals/types"
 	"github.com/spf13/cobra"
 )
 
+var (
+	errUnknownCity   = errors.New("unknown city")
+	errAmbiguousCity = errors.New("city in more than one timezone")
+)
+
 // addCmd represents the add command
 var addCmd = &cobra.Command{
 	Use:   "add [name] [city] [time zone]",
 	Short: "Add a new colleague",
-	Args:  cobra.ExactArgs(3),
-	RunE:  addFunc,
+	Long: `Add a new colleague with their city and timezone.
+
+The timezone may be left out for most cities: it is looked up in a list of
+cities that works offline. When several cities share the name, the one a
+timezone is named after is taken, e.g. London for Europe/London. Otherwise,
+such as for Portland in Oregon and in Maine, add the region or country, e.g.
+"Portland, OR", or give the timezone. When the city is unknown or ambiguous,
+teamtime asks for the timezone when run in a terminal.
+
+Examples:
+  teamtime add "Priya" "Pune"
+  teamtime add "Sam" "Portland, Maine"
+  teamtime add "Lúcio" "Poggibonsi" "Europe/Rome"`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: addFunc,
 }
 
 func addFunc(cmd *cobra.Command, args []string) error {
@@ -21,7 +46,29 @@ func addFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	newColleague, err := svc.AddColleague(args[0], args[1], args[2])
+	sched, err := readScheduleFlags(cmd)
+	if err != nil {
+		return fmt.Errorf("add command: %w", err)
+	}
+
+	tags, err := readTagsFlag(cmd, "tag")
+	if err != nil {
+		return fmt.Errorf("add command: %w", err)
+	}
+
+	opts := sched.options()
+	if tags != nil {
+		opts = append(opts, types.WithTags(tags))
+	}
+
+	var tz string
+	if len(args) == 3 {
+		tz = args[2]
+	} else if tz, err = cityTimezone(args[1], isInteractive()); err != nil {
+		return fmt.Errorf("add command: %w", err)
+	}
+
+	newColleague, err := svc.AddColleague(args[0], args[1], tz, opts...)
 	if err != nil {
 		return fmt.Errorf("add command: %w", err)
 	}
@@ -31,6 +78,146 @@ func addFunc(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// cityTimezone looks up the timezone of city, preferring the city a timezone
+// is named after among those sharing its name. When the city is unknown or
+// still ambiguous, it asks for the timezone if
+// prompt is set, and otherwise lists the cities in the error
+func cityTimezone(city string, prompt bool) (string, error) {
+	places := geo.Lookup(city)
+	if tz, ok := geo.Timezone(places); ok {
+		return tz, nil
+	}
+
+	if prompt {
+		if tz := askTimezone(city, fmt.Sprintf("Timezone of %s", city), places); tz != "" {
+			return tz, nil
+		}
+	}
+
+	if len(places) == 0 {
+		return "", fmt.Errorf("%w: %q, give its timezone after the city, e.g. Europe/London", errUnknownCity, city)
+	}
+
+	lines := make([]string, len(places))
+	for i, p := range places {
+		lines[i] = fmt.Sprintf("  %-30s %s", p, p.Timezone)
+	}
+	example := places[0].Name + ", " + places[0].Country
+	if places[0].RegionCode != "" {
+		example = places[0].Name + ", " + places[0].RegionCode
+	}
+	return "", fmt.Errorf("%w: %q\n%s\nadd the region or country to the city, e.g. %q, or give the timezone after it",
+		errAmbiguousCity, city, strings.Join(lines, "\n"), example)
+}
+
+// askTimezone asks for a timezone until the answer is valid or empty. places
+// are the cities called city, listed so that one can be picked by number
+func askTimezone(city, question string, places []geo.Place) string {
+	if len(places) > 0 {
+		infoStyle := styles.NewStyles().Cyan()
+		fmt.Fprintln(os.Stderr, infoStyle.Render(fmt.Sprintf("%s: %s", errAmbiguousCity, city)))
+		for i, p := range places {
+			fmt.Fprintf(os.Stderr, "  %d. %s (%s)\n", i+1, p, p.Timezone)
+		}
+	}
+
+	for {
+		answer := ask(question + ", e.g. Europe/London (empty to skip): ")
+		if answer == "" {
+			return ""
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(places) {
+			return places[n-1].Timezone
+		}
+		if _, err := time.LoadLocation(answer); err == nil {
+			return answer
+		}
+		fmt.Fprintf(os.Stderr, "%q is not a timezone\n", answer)
+	}
+}
+
+// scheduleFlags holds the values of the schedule flags shared by add and edit.
+// Fields are nil when the flag was not given
+type scheduleFlags struct {
+	workHours     *types.Hours
+	extendedHours *types.Hours
+	workDays      types.Weekdays
+}
+
+func addScheduleFlags(cmd *cobra.Command) {
+	cmd.Flags().String("work-hours", "", "working hours, e.g. 8-16 (default 9-17)")
+	cmd.Flags().String("extended-hours", "", "extended hours, e.g. 7-20 (default 7-20)")
+	cmd.Flags().String("work-days", "", "working days, e.g. sun-thu or mon,tue,wed (default mon-fri)")
+}
+
+func readScheduleFlags(cmd *cobra.Command) (scheduleFlags, error) {
+	var sched scheduleFlags
+
+	workHours, err := cmd.Flags().GetString("work-hours")
+	if err != nil {
+		return sched, fmt.Errorf("failed to get work-hours flag: %w", err)
+	}
+	if workHours != "" {
+		h, err := types.ParseHours(workHours)
+		if err != nil {
+			return sched, fmt.Errorf("work hours: %w", err)
+		}
+		sched.workHours = &h
+	}
+
+	extendedHours, err := cmd.Flags().GetString("extended-hours")
+	if err != nil {
+		return sched, fmt.Errorf("failed to get extended-hours flag: %w", err)
+	}
+	if extendedHours != "" {
+		h, err := types.ParseHours(extendedHours)
+		if err != nil {
+			return sched, fmt.Errorf("extended hours: %w", err)
+		}
+		sched.extendedHours = &h
+	}
+
+	workDays, err := cmd.Flags().GetString("work-days")
+	if err != nil {
+		return sched, fmt.Errorf("failed to get work-days flag: %w", err)
+	}
+	if workDays != "" {
+		d, err := types.ParseWeekdays(workDays)
+		if err != nil {
+			return sched, fmt.Errorf("work days: %w", err)
+		}
+		sched.workDays = d
+	}
+
+	return sched, nil
+}
+
+func (s scheduleFlags) options() []types.Option {
+	var opts []types.Option
+	if s.workHours != nil {
+		opts = append(opts, types.WithWorkHours(*s.workHours))
+	}
+	if s.extendedHours != nil {
+		opts = append(opts, types.WithExtendedHours(*s.extendedHours))
+	}
+	if s.workDays != nil {
+		opts = append(opts, types.WithWorkDays(s.workDays))
+	}
+	return opts
+}
+
+// readTagsFlag parses a repeatable tags flag, each value possibly a comma
+// separated list
+func readTagsFlag(cmd *cobra.Command, name string) (types.Tags, error) {
+	values, err := cmd.Flags().GetStringArray(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s flag: %w", name, err)
+	}
+	return types.ParseTags(values...)
+}
+
 func init() {
+	addScheduleFlags(addCmd)
+	addCmd.Flags().StringArray("tag", nil, "tag the colleague, repeatable or comma separated, e.g. --tag backend --tag oncall")
 	rootCmd.AddCommand(addCmd)
 }
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCityTimezone(t *testing.T) {
+	tests := []struct {
+		city    string
+		prompt  bool
+		answer  string
+		want    string
+		wantErr error
+	}{
+		{city: "Pune", want: "Asia/Kolkata"},
+		{city: "new york", want: "America/New_York"},
+		{city: "Portland, ME", want: "America/New_York"},
+		{city: "London", want: "Europe/London"},
+		{city: "London, Ontario", want: "America/Toronto"},
+		{city: "Portland", wantErr: errAmbiguousCity},
+		{city: "Atlantis", wantErr: errUnknownCity},
+		{city: "Portland", prompt: true, answer: "1\n", want: "America/Los_Angeles"},
+		{city: "Atlantis", prompt: true, answer: "Not/AZone\nEurope/Athens\n", want: "Europe/Athens"},
+		{city: "Atlantis", prompt: true, answer: "\n", wantErr: errUnknownCity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.city, func(t *testing.T) {
+			saved := stdin
+			stdin = bufio.NewReader(strings.NewReader(tt.answer))
+			t.Cleanup(func() { stdin = saved })
+
+			got, err := cityTimezone(tt.city, tt.prompt)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
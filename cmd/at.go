@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	errMissingTime = errors.New("missing time, e.g. 15:00, 3pm or tomorrow 9am")
+	clockPattern   = regexp.MustCompile(`^(\d{1,2})(?:[:.](\d{2}))?\s*(am|pm)?$`)
+)
+
+var weekdaysByName = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// atCmd represents the at command
+var atCmd = &cobra.Command{
+	Use:   "at <time> [query...]",
+	Short: "Show local time for colleagues at a given time",
+	Long: `Show local time and availability for colleagues at a given time.
+
+The time accepts forms such as 15:00, 3pm, 9:30am, noon, tomorrow 9am or
+thu 15:00, with the day before or after the clock. It is read in --tz
+(default local) on --date (default today).
+
+Each argument after the time selects colleagues with the query language of
+check, e.g. priya or "tag:backend status:work", where statuses are those at
+the given time. Colleagues selected by any of them are shown, and everyone
+without arguments or with --all.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: atFunc,
+}
+
+func init() {
+	atCmd.Flags().String("tz", "", "timezone the time is expressed in (default local)")
+	atCmd.Flags().StringP("date", "d", "", "date as YYYY-MM-DD (default today)")
+	atCmd.Flags().BoolP("all", "a", false, "show everyone, the same as no query")
+	rootCmd.AddCommand(atCmd)
+}
+
+func atFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get colleague service: %w", err)
+	}
+
+	tz, err := cmd.Flags().GetString("tz")
+	if err != nil {
+		return fmt.Errorf("failed to get tz flag: %w", err)
+	}
+
+	loc, err := loadLocation(tz)
+	if err != nil {
+		return err
+	}
+
+	date, err := cmd.Flags().GetString("date")
+	if err != nil {
+		return fmt.Errorf("failed to get date flag: %w", err)
+	}
+
+	instant, queries, err := parseAt(args, time.Now().In(loc), date)
+	if err != nil {
+		return fmt.Errorf("at command: %w", err)
+	}
+
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return fmt.Errorf("failed to get all flag: %w", err)
+	}
+
+	colleagues, err := getParticipants(svc, queries, all, instant)
+	if err != nil {
+		return fmt.Errorf("at command: %w", err)
+	}
+
+	headingStyle := styles.NewStyles().Cyan()
+	fmt.Println()
+	fmt.Println(headingStyle.Render(fmt.Sprintf("At %s (%s)", instant.Format("15:04 Mon 02 Jan 2006"), instant.Location())))
+	return displayColleagues(svc, colleagues, checkQuery{}, instant)
+}
+
+// parseAt reads a time expression from the start of args and returns the
+// instant it refers to, relative to now, along with the remaining arguments.
+// The expression is a clock and an optional day in either order, e.g.
+// "friday 9am" or "9am friday", and the first argument may hold all of it
+func parseAt(args []string, now time.Time, date string) (time.Time, []string, error) {
+	var tokens []string
+	if len(args) > 0 {
+		tokens = append(strings.Fields(strings.ToLower(args[0])), args[1:]...)
+	}
+
+	day := now
+	dayGiven, clockGiven := false, false
+	var hour, minute int
+	for len(tokens) > 0 && !(dayGiven && clockGiven) {
+		token := strings.ToLower(tokens[0])
+		if d, ok := parseDay(token, now); ok && !dayGiven {
+			if date != "" {
+				return time.Time{}, nil, fmt.Errorf("use either --date or a day in the time, not both")
+			}
+			day, dayGiven = d, true
+			tokens = tokens[1:]
+			continue
+		}
+		if clockGiven {
+			break
+		}
+
+		tokens = tokens[1:]
+		if len(tokens) > 0 && (strings.EqualFold(tokens[0], "am") || strings.EqualFold(tokens[0], "pm")) {
+			token += strings.ToLower(tokens[0])
+			tokens = tokens[1:]
+		}
+
+		var err error
+		if hour, minute, err = parseClock(token, now); err != nil {
+			return time.Time{}, nil, err
+		}
+		clockGiven = true
+	}
+
+	if !clockGiven {
+		return time.Time{}, nil, errMissingTime
+	}
+
+	if date != "" {
+		var err error
+		day, err = time.ParseInLocation("2006-01-02", date, now.Location())
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	instant := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+	return instant, tokens, nil
+}
+
+// parseDay recognises relative day words and weekday names. Weekdays refer to
+// the next occurrence, today included
+func parseDay(token string, now time.Time) (time.Time, bool) {
+	switch token {
+	case "today":
+		return now, true
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), true
+	case "yesterday":
+		return now.AddDate(0, 0, -1), true
+	}
+
+	if wd, ok := weekdaysByName[token]; ok {
+		ahead := (int(wd) - int(now.Weekday()) + 7) % 7
+		return now.AddDate(0, 0, ahead), true
+	}
+
+	return time.Time{}, false
+}
+
+func parseClock(s string, now time.Time) (int, int, error) {
+	switch s {
+	case "now":
+		return now.Hour(), now.Minute(), nil
+	case "noon", "midday":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	}
+
+	m := clockPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid time %q, e.g. 15:00, 3pm or 9:30am", s)
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid time %q, hour must be between 1 and 12", s)
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0, 0, fmt.Errorf("invalid time %q, hour must be between 0 and 23", s)
+		}
+	}
+
+	if minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time %q, minutes must be between 0 and 59", s)
+	}
+
+	return hour, minute, nil
+}
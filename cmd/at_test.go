@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseAt(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	// Tuesday
+	now := time.Date(2025, 11, 18, 10, 20, 0, 0, london)
+
+	tests := []struct {
+		name      string
+		args      []string
+		date      string
+		want      string
+		wantRest  []string
+		wantError bool
+	}{
+		{name: "24 hour clock", args: []string{"15:00"}, want: "2025-11-18 15:00"},
+		{name: "hour only", args: []string{"9"}, want: "2025-11-18 09:00"},
+		{name: "pm", args: []string{"3pm"}, want: "2025-11-18 15:00"},
+		{name: "am with minutes", args: []string{"9:30am"}, want: "2025-11-18 09:30"},
+		{name: "midnight as 12am", args: []string{"12am"}, want: "2025-11-18 00:00"},
+		{name: "noon as 12pm", args: []string{"12pm"}, want: "2025-11-18 12:00"},
+		{name: "separate meridiem", args: []string{"3", "PM"}, want: "2025-11-18 15:00"},
+		{name: "noon", args: []string{"noon"}, want: "2025-11-18 12:00"},
+		{name: "now", args: []string{"now"}, want: "2025-11-18 10:20"},
+		{name: "tomorrow quoted", args: []string{"tomorrow 9am"}, want: "2025-11-19 09:00"},
+		{name: "tomorrow separate", args: []string{"tomorrow", "9am", "priya"}, want: "2025-11-19 09:00", wantRest: []string{"priya"}},
+		{name: "day after clock", args: []string{"9am", "tomorrow"}, want: "2025-11-19 09:00"},
+		{name: "weekday ahead", args: []string{"thursday", "15:00"}, want: "2025-11-20 15:00"},
+		{name: "weekday today", args: []string{"tue", "15:00"}, want: "2025-11-18 15:00"},
+		{name: "weekday wraps", args: []string{"mon", "15:00"}, want: "2025-11-24 15:00"},
+		{name: "weekday before clock", args: []string{"friday", "9:00", "Lucio"}, want: "2025-11-21 09:00", wantRest: []string{"Lucio"}},
+		{name: "weekday after clock", args: []string{"9:00", "friday", "Lucio"}, want: "2025-11-21 09:00", wantRest: []string{"Lucio"}},
+		{name: "weekday before clock quoted", args: []string{"fri 9 am", "Lucio"}, want: "2025-11-21 09:00", wantRest: []string{"Lucio"}},
+		{name: "weekday after clock quoted", args: []string{"9 am fri", "Lucio"}, want: "2025-11-21 09:00", wantRest: []string{"Lucio"}},
+		{name: "second day is a query", args: []string{"tomorrow", "9am", "friday"}, want: "2025-11-19 09:00", wantRest: []string{"friday"}},
+		{name: "date flag", args: []string{"15:00", "priya", "lucio"}, date: "2025-12-01", want: "2025-12-01 15:00", wantRest: []string{"priya", "lucio"}},
+		{name: "date flag and day", args: []string{"tomorrow", "15:00"}, date: "2025-12-01", wantError: true},
+		{name: "invalid date", args: []string{"15:00"}, date: "01/12/2025", wantError: true},
+		{name: "missing time", args: []string{"tomorrow"}, wantError: true},
+		{name: "invalid hour", args: []string{"25:00"}, wantError: true},
+		{name: "invalid pm hour", args: []string{"13pm"}, wantError: true},
+		{name: "invalid minutes", args: []string{"10:75"}, wantError: true},
+		{name: "not a time", args: []string{"priya"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := parseAt(tt.args, now, tt.date)
+
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if formatted := got.Format("2006-01-02 15:04"); formatted != tt.want {
+				t.Errorf("got %s, want %s", formatted, tt.want)
+			}
+
+			if got.Location() != london {
+				t.Errorf("got location %s, want %s", got.Location(), london)
+			}
+
+			if len(rest) != 0 || len(tt.wantRest) != 0 {
+				if !slices.Equal(rest, tt.wantRest) {
+					t.Errorf("got remaining args %v, want %v", rest, tt.wantRest)
+				}
+			}
+		})
+	}
+}
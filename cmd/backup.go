@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/spf13/cobra"
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manage automatic backups of the colleagues file",
+}
+
+// backupListCmd represents the backup list command
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available backups, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  backupListFunc,
+}
+
+func init() {
+	backupCmd.AddCommand(backupListCmd)
+	rootCmd.AddCommand(backupCmd)
+}
+
+func backupListFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	backups, err := svc.Backups()
+	if err != nil {
+		return fmt.Errorf("backup list command: %w", err)
+	}
+
+	if len(backups) == 0 {
+		fmt.Println(styles.NewStyles().Cyan().Render("no backups found"))
+		return nil
+	}
+
+	heading := styles.NewStyles().Bold()
+	fmt.Println()
+	fmt.Printf("%s | %s | %s\n",
+		heading.Render(fmt.Sprintf("%-4s", "#")),
+		heading.Render(fmt.Sprintf("%-44s", "Snapshot")),
+		heading.Render(fmt.Sprintf("%-20s", "Created")))
+	fmt.Printf("%-4s | %-44s | %-20s\n",
+		strings.Repeat("-", 4),
+		strings.Repeat("-", 44),
+		strings.Repeat("-", 20))
+	for idx, b := range backups {
+		fmt.Printf("%-4d | %-44s | %-20s\n",
+			idx+1,
+			b.Name,
+			b.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Println()
+	return nil
+}
@@ -9,38 +9,50 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/matteo-gildone/teamtime/internals/report"
+	"github.com/matteo-gildone/teamtime/internals/schedule"
 	"github.com/matteo-gildone/teamtime/internals/service"
 	"github.com/matteo-gildone/teamtime/internals/styles"
 	"github.com/matteo-gildone/teamtime/internals/types"
 	"github.com/spf13/cobra"
 )
 
-const (
-	extendedStart  int = 7
-	extendedEnd    int = 20
-	workHoursStart int = 9
-	workHoursEnd   int = 17
-)
-
-type timeClassification string
-
-const (
-	timeWork     timeClassification = "work"
-	timeExtended timeClassification = "extended"
-	timeOff      timeClassification = "off"
-)
-
 // checkCmd represents the list command
 var checkCmd = &cobra.Command{
-	Use:   "check",
+	Use:   "check [query...]",
 	Short: "Show current local time for all colleagues",
-	Args:  cobra.ExactArgs(1),
-	RunE:  checkFunc,
+	Long: `Show the current local time of everyone, or of the colleagues matching a query.
+
+A query is made of terms that must all match, e.g.
+
+  teamtime check city:berlin tz:Asia/* tag:oncall status:work
+
+Terms without a field match names, or close names when no name contains
+them, e.g. prya for Priya. Fields are name, city, tz, tag, id and
+status (work, extended, off or dayoff). Comma separated values are
+alternatives, '*' and '?' are wildcards, and a term prefixed with '!' or '-'
+must not match (put '--' before terms starting with '-').`,
+	Args: cobra.ArbitraryArgs,
+	RunE: checkFunc,
 }
 
+const outputTable = "table"
+
 func init() {
 	checkCmd.Flags().BoolP("watch", "w", false, "continuously update times")
 	checkCmd.Flags().IntP("interval", "i", 10, "update interval in minutes")
+	checkCmd.Flags().StringP("output", "o", outputTable,
+		fmt.Sprintf("output format: %s or %s", outputTable, strings.Join(report.FormatNames(), ", ")))
+	checkCmd.Flags().StringP("format", "f", "", "Go template rendered for each colleague, e.g. '{{.Name}} {{.LocalTime.Format \"15:04\"}}'")
+	checkCmd.Flags().BoolP("all", "a", false, "show everyone, the same as an empty query")
+	checkCmd.Flags().StringArrayP("tag", "t", nil, "only show colleagues with this tag, repeatable or comma separated")
+	checkCmd.Flags().Bool("any-tag", false, "show colleagues with any of the tags rather than all of them")
+	checkCmd.Flags().Bool("show-tags", false, "add a tags column to the table")
+	checkCmd.Flags().StringArrayP("status", "s", nil, "only show colleagues with this availability now: work, extended, off or dayoff (repeatable)")
+	checkCmd.Flags().Bool("available", false, "only show colleagues in working hours now, the same as --status work")
+	checkCmd.Flags().Bool("exit-code", false, "exit with status 1 when no colleague matches")
+	checkCmd.Flags().String("sort", "", fmt.Sprintf("sort colleagues by %s", strings.Join(report.SortKeys, ", ")))
+	checkCmd.Flags().String("group-by", "", fmt.Sprintf("group the table by %s", strings.Join(report.GroupKeys, ", ")))
 	rootCmd.AddCommand(checkCmd)
 }
 
@@ -50,33 +62,221 @@ func checkFunc(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get colleague service: %w", err)
 	}
 
+	query, err := readCheckQuery(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	exitCode, err := cmd.Flags().GetBool("exit-code")
+	if err != nil {
+		return fmt.Errorf("failed to get exit-code flag: %w", err)
+	}
+
 	watchMode, err := cmd.Flags().GetBool("watch")
 	if err != nil {
 		return fmt.Errorf("failed to get watch flag: %w", err)
 	}
 
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("failed to get output flag: %w", err)
+	}
+
+	if _, ok := report.Formatters[output]; !ok && output != outputTable {
+		return fmt.Errorf("unknown output format %q, use %s or %s", output, outputTable, strings.Join(report.FormatNames(), ", "))
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return fmt.Errorf("failed to get format flag: %w", err)
+	}
+
+	if format != "" {
+		if cmd.Flags().Changed("output") {
+			return fmt.Errorf("use either --output or --format, not both")
+		}
+		if watchMode {
+			return fmt.Errorf("watch mode does not support --format")
+		}
+		if query.groupBy != "" {
+			return fmt.Errorf("--group-by only supports %s output", outputTable)
+		}
+		matched, err := runTemplate(svc, query, format)
+		return checkExitCode(cmd, exitCode, matched, err)
+	}
+
 	if watchMode {
+		if exitCode {
+			return fmt.Errorf("watch mode does not support --exit-code")
+		}
+		if output != outputTable {
+			return fmt.Errorf("watch mode only supports %s output", outputTable)
+		}
 		watchInterval, err := cmd.Flags().GetInt("interval")
 		if err != nil {
 			return fmt.Errorf("failed to get interval flag: %w", err)
 		}
-		return runWatch(cmd.Context(), svc, args[0], watchInterval)
+		return runWatch(cmd.Context(), svc, query, watchInterval)
 	}
 
-	return runOnce(svc, args[0])
+	if query.groupBy != "" && output != outputTable {
+		return fmt.Errorf("--group-by only supports %s output", outputTable)
+	}
+
+	matched, err := runOnce(svc, query, output)
+	return checkExitCode(cmd, exitCode, matched, err)
 }
 
-func runOnce(svc *service.ColleagueService, query string) error {
-	colleagues, err := getColleagues(svc, query)
-	if err != nil {
+// checkExitCode turns an empty result into a silent exit status of 1 when
+// --exit-code is set, so that scripts can test whether anyone matched
+func checkExitCode(cmd *cobra.Command, exitCode bool, matched int, err error) error {
+	if err != nil || !exitCode || matched > 0 {
 		return err
 	}
+	cmd.SilenceErrors = true
+	return &exitError{code: 1, err: errNoMatch}
+}
 
-	displayColleagues(colleagues, query)
-	return nil
+// checkQuery selects the colleagues shown by check and how the table looks
+type checkQuery struct {
+	query    service.Query
+	showTags bool
+	sortBy   string
+	groupBy  string
 }
 
-func runWatch(ctx context.Context, svc *service.ColleagueService, query string, interval int) error {
+func readCheckQuery(cmd *cobra.Command, args []string) (checkQuery, error) {
+	var q checkQuery
+
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return q, fmt.Errorf("failed to get all flag: %w", err)
+	}
+	if all && len(args) > 0 {
+		return q, fmt.Errorf("use either --all or a query, not both")
+	}
+
+	if q.query, err = service.ParseQuery(args...); err != nil {
+		return q, err
+	}
+
+	tags, err := readTagsFlag(cmd, "tag")
+	if err != nil {
+		return q, err
+	}
+	anyTag, err := cmd.Flags().GetBool("any-tag")
+	if err != nil {
+		return q, fmt.Errorf("failed to get any-tag flag: %w", err)
+	}
+	q.query = q.query.WithTags(tags, anyTag)
+
+	statuses, err := cmd.Flags().GetStringArray("status")
+	if err != nil {
+		return q, fmt.Errorf("failed to get status flag: %w", err)
+	}
+	available, err := cmd.Flags().GetBool("available")
+	if err != nil {
+		return q, fmt.Errorf("failed to get available flag: %w", err)
+	}
+	if available {
+		if len(statuses) > 0 {
+			return q, fmt.Errorf("use either --available or --status, not both")
+		}
+		statuses = []string{string(schedule.StatusWork)}
+	}
+	if q.query, err = q.query.WithStatuses(statuses...); err != nil {
+		return q, err
+	}
+
+	if q.showTags, err = cmd.Flags().GetBool("show-tags"); err != nil {
+		return q, fmt.Errorf("failed to get show-tags flag: %w", err)
+	}
+	if q.sortBy, err = cmd.Flags().GetString("sort"); err != nil {
+		return q, fmt.Errorf("failed to get sort flag: %w", err)
+	}
+	if q.groupBy, err = cmd.Flags().GetString("group-by"); err != nil {
+		return q, fmt.Errorf("failed to get group-by flag: %w", err)
+	}
+
+	// reject unknown keys up front, even when nobody matches
+	if q.sortBy != "" {
+		if err := report.SortRows(nil, q.sortBy); err != nil {
+			return q, err
+		}
+	}
+	if q.groupBy != "" {
+		if _, err := report.GroupRows(nil, q.groupBy); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+// buildRows computes the rows for colleagues at now, in the requested order
+func (q checkQuery) buildRows(colleagues []types.Colleague, now time.Time) ([]report.Row, error) {
+	rows := report.BuildRows(colleagues, now)
+	if q.sortBy == "" {
+		return rows, nil
+	}
+	if err := report.SortRows(rows, q.sortBy); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// emptyMessage explains that nothing matched the query
+func (q checkQuery) emptyMessage() string {
+	if q.query.IsEmpty() {
+		return "no colleagues found"
+	}
+	return fmt.Sprintf("no colleague matches: %s", q.query)
+}
+
+func findColleagues(svc *service.ColleagueService, q checkQuery, now time.Time) (types.ColleagueList, error) {
+	return svc.SearchFuzzy(q.query, now)
+}
+
+// runOnce shows the matching colleagues and returns how many there were
+func runOnce(svc *service.ColleagueService, query checkQuery, output string) (int, error) {
+	now := time.Now()
+	colleagues, err := findColleagues(svc, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	if output == outputTable {
+		return len(colleagues), displayColleagues(svc, colleagues, query, now)
+	}
+
+	rows, err := query.buildRows(colleagues, now)
+	if err != nil {
+		return 0, err
+	}
+	return len(colleagues), report.Formatters[output](os.Stdout, rows)
+}
+
+// runTemplate renders format for each matching colleague and returns how many
+// there were
+func runTemplate(svc *service.ColleagueService, query checkQuery, format string) (int, error) {
+	tmpl, err := report.NewTemplate(format, styles.NewStyles())
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	colleagues, err := findColleagues(svc, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := query.buildRows(colleagues, now)
+	if err != nil {
+		return 0, err
+	}
+	return len(colleagues), report.FormatTemplate(os.Stdout, tmpl, rows)
+}
+
+func runWatch(ctx context.Context, svc *service.ColleagueService, query checkQuery, interval int) error {
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
@@ -104,40 +304,57 @@ func runWatch(ctx context.Context, svc *service.ColleagueService, query string,
 
 }
 
-func getColleagues(svc *service.ColleagueService, query string) (types.ColleagueList, error) {
-	if query == "all" {
-		return svc.AllColleagues()
+func displayColleagues(svc *service.ColleagueService, colleagues []types.Colleague, query checkQuery, now time.Time) error {
+	if len(colleagues) == 0 {
+		return displayEmptyMessage(svc, query)
 	}
 
-	return svc.FindColleague(query)
-}
+	rows, err := query.buildRows(colleagues, now)
+	if err != nil {
+		return err
+	}
 
-func displayColleagues(colleagues []types.Colleague, query string) {
-	if len(colleagues) == 0 {
-		displayEmptyMessage(query)
-		return
+	if query.groupBy == "" {
+		renderTable(rows, query.showTags)
+		return nil
 	}
 
-	renderTable(colleagues)
+	groups, err := report.GroupRows(rows, query.groupBy)
+	if err != nil {
+		return err
+	}
+	renderGroups(groups, query.showTags)
+	return nil
 }
 
-func displayEmptyMessage(query string) {
+// displayEmptyMessage explains that nothing matched, suggesting the names
+// closest to the name terms of the query
+func displayEmptyMessage(svc *service.ColleagueService, query checkQuery) error {
 	msgStyle := styles.NewStyles().Cyan()
-	if query == "all" {
-		fmt.Println(msgStyle.Render("no colleagues found"))
-	} else {
-		fmt.Println(msgStyle.Render(fmt.Sprintf("no colleague found with name: %q\n", query)))
-	}
+	fmt.Println(msgStyle.Render(query.emptyMessage()))
 
+	names := query.query.Names()
+	if len(names) == 0 {
+		return nil
+	}
+	suggestions, err := svc.Suggest(strings.Join(names, " "))
+	if err != nil {
+		return err
+	}
+	if len(suggestions) > 0 {
+		fmt.Println(msgStyle.Render(fmt.Sprintf("did you mean %s?", service.DidYouMean(suggestions))))
+	}
+	return nil
 }
 
 func clearScreen() {
 	fmt.Print("\033[H\033[2J")
 }
 
-func renderWatchScreen(svc *service.ColleagueService, query string, interval int) error {
+func renderWatchScreen(svc *service.ColleagueService, query checkQuery, interval int) error {
 	clearScreen()
-	colleagues, err := getColleagues(svc, query)
+	now := time.Now()
+	colleagues, err := findColleagues(svc, query, now)
 	if err != nil {
 		return err
 	}
@@ -147,77 +364,95 @@ func renderWatchScreen(svc *service.ColleagueService, query string, interval int
 
 	fmt.Println(watchStyle.Render(fmt.Sprintf("⟳ Watch mode (updates every %d mins) - Press Ctrl+C to exit", interval)))
 	fmt.Println()
-	displayColleagues(colleagues, query)
-	fmt.Println(dimStyle.Render(fmt.Sprintf("Last updated: %s", time.Now().Format("15:04:05"))))
+	if err := displayColleagues(svc, colleagues, query, now); err != nil {
+		return err
+	}
+	fmt.Println(dimStyle.Render(fmt.Sprintf("Last updated: %s", now.Format("15:04:05"))))
 	return nil
 }
 
-func renderTable(colleagues types.ColleagueList) {
-	plainStyle := styles.NewStyles()
-	heading := plainStyle.Bold()
-	invalidTZ := heading.Red()
-	if len(colleagues) == 0 {
+func renderTable(rows []report.Row, showTags bool) {
+	if len(rows) == 0 {
 		return
 	}
-	now := time.Now()
 
 	fmt.Println()
-	fmt.Printf("%s | %s | %s\n",
-		heading.Render(fmt.Sprintf("%-4s", "ID")),
-		heading.Render(fmt.Sprintf("%-20s", "Name")),
-		heading.Render(fmt.Sprintf("%-32s", "Local Time")))
+	renderRows(rows, showTags)
+	fmt.Println()
+	renderLegend(styles.NewStyles())
+}
 
-	fmt.Printf("%-4s | %-20s | %-20s\n",
-		strings.Repeat("-", 4),
-		strings.Repeat("-", 20),
-		strings.Repeat("-", 32))
-	for idx, c := range colleagues {
-		loc, err := time.LoadLocation(c.Timezone)
-		if err != nil {
-			fmt.Printf("%-4d | %-20s | %s\n",
-				idx+1,
-				c.Name,
-				invalidTZ.Render(fmt.Sprintf("%-32s", "ERROR: Invalid TZ")))
-			continue
-		}
-		local := now.In(loc)
-		timeDisplay := getDisplayTime(local, plainStyle)
-		fmt.Printf("%-4d | %-20s | %s\n",
-			idx+1,
-			c.Name,
-			timeDisplay)
+// renderGroups renders a table under a heading for each group, with a single
+// legend at the end
+func renderGroups(groups []report.Group, showTags bool) {
+	plainStyle := styles.NewStyles()
+	heading := plainStyle.Bold().Cyan()
+	for _, g := range groups {
+		fmt.Println()
+		fmt.Println(heading.Render(g.Title))
+		renderRows(g.Rows, showTags)
 	}
 	fmt.Println()
 	renderLegend(plainStyle)
 }
 
-func classifyTimeOfDay(hour int) timeClassification {
-	if hour >= workHoursStart && hour < workHoursEnd {
-		return timeWork
-	}
+// renderRows renders the table header and rows
+func renderRows(rows []report.Row, showTags bool) {
+	plainStyle := styles.NewStyles()
+	heading := plainStyle.Bold()
+	invalidTZ := heading.Red()
 
-	if (hour >= extendedStart && hour < workHoursStart) || (hour >= workHoursEnd && hour < extendedEnd) {
-		return timeExtended
+	// tagsColumn renders the optional trailing tags column
+	tagsColumn := func(s string) string {
+		if !showTags {
+			return ""
+		}
+		return " | " + s
 	}
 
-	return timeOff
+	fmt.Printf("%s | %s | %s%s\n",
+		heading.Render(fmt.Sprintf("%-8s", "ID")),
+		heading.Render(fmt.Sprintf("%-20s", "Name")),
+		heading.Render(fmt.Sprintf("%-32s", "Local Time")),
+		tagsColumn(heading.Render("Tags")))
+
+	fmt.Printf("%-8s | %-20s | %-20s%s\n",
+		strings.Repeat("-", 8),
+		strings.Repeat("-", 20),
+		strings.Repeat("-", 32),
+		tagsColumn(strings.Repeat("-", 20)))
+	for _, r := range rows {
+		if r.Err != nil {
+			fmt.Printf("%-8s | %-20s | %s%s\n",
+				r.ID,
+				r.Name,
+				invalidTZ.Render(fmt.Sprintf("%-32s", "ERROR: Invalid TZ")),
+				tagsColumn(r.Tags.String()))
+			continue
+		}
+		timeDisplay := getDisplayTime(r.LocalTime, r.Status, plainStyle)
+		fmt.Printf("%-8s | %-20s | %s%s\n",
+			r.ID,
+			r.Name,
+			timeDisplay,
+			tagsColumn(r.Tags.String()))
+	}
 }
 
-func getDisplayTime(localTime time.Time, plainStyle styles.Style) string {
-	hour := localTime.Hour()
+func getDisplayTime(localTime time.Time, status schedule.Status, plainStyle styles.Style) string {
 	timeStr := localTime.Format("15:04 (Mon 02 Jan)")
-	base := plainStyle.Bold()
-
-	switch classifyTimeOfDay(hour) {
-	case timeWork:
-		return base.Cyan().Render(fmt.Sprintf("%-32s", timeStr))
-	case timeExtended:
-		return base.Yellow().Render(fmt.Sprintf("%-32s", timeStr+" [Extended]"))
-	case timeOff:
-		return base.Red().Render(fmt.Sprintf("%-32s", timeStr+" [Off]"))
-	default:
-		return base.Render(fmt.Sprintf("%-32s", timeStr))
+	style := report.StatusStyle(plainStyle, status)
+
+	switch status {
+	case schedule.StatusExtended:
+		timeStr += " [Extended]"
+	case schedule.StatusOff:
+		timeStr += " [Off]"
+	case schedule.StatusDayOff:
+		timeStr += " [Day off]"
 	}
+
+	return style.Render(fmt.Sprintf("%-32s", timeStr))
 }
 
 func renderLegend(plainStyle styles.Style) {
@@ -225,8 +460,9 @@ func renderLegend(plainStyle styles.Style) {
 		return
 	}
 	fmt.Println(plainStyle.Render("Availability:"))
-	fmt.Println(plainStyle.Cyan().Bold().Render("    Cyan") + " - Work hours (9am-5pm)")
-	fmt.Println(plainStyle.Yellow().Bold().Render("    Yellow") + " - Extended hours")
+	fmt.Println(plainStyle.Cyan().Bold().Render("    Cyan") + " - Work hours (default 9am-5pm)")
+	fmt.Println(plainStyle.Yellow().Bold().Render("    Yellow") + " - Extended hours (default 7am-8pm)")
 	fmt.Println(plainStyle.Red().Bold().Render("    Red") + " - Off hours")
+	fmt.Println(plainStyle.Dim().Render("    Dim") + " - Weekend / day off (default Mon-Fri)")
 	fmt.Println()
 }
@@ -5,54 +5,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/matteo-gildone/teamtime/internals/schedule"
 	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/matteo-gildone/teamtime/internals/types"
 )
 
-func TestClassifyTimeOfDay(t *testing.T) {
-	tests := []struct {
-		name string
-		hour int
-		want timeClassification
-	}{
-		{
-			name: "off too late evening",
-			hour: 3,
-			want: timeOff,
-		},
-		{
-			name: "off too early morning",
-			hour: 23,
-			want: timeOff,
-		},
-		{
-			name: "work time",
-			hour: 9,
-			want: timeWork,
-		},
-		{
-			name: "extended work time morning",
-			hour: 7,
-			want: timeExtended,
-		},
-		{
-			name: "extended work time afternoon",
-			hour: 17,
-			want: timeExtended,
-		},
-	}
-
-	for _, tt := range tests {
-		got := classifyTimeOfDay(tt.hour)
-		if got != tt.want {
-			t.Errorf("got %q, want %q, given %v", got, tt.want, tt.hour)
-		}
-	}
-}
-
 func TestGetDisplayTime(t *testing.T) {
 	tests := []struct {
 		name           string
+		day            int
 		hour           int
+		workDays       types.Weekdays
 		noColor        bool
 		wantContains   []string
 		wantNotContain []string
@@ -113,13 +76,51 @@ func TestGetDisplayTime(t *testing.T) {
 			wantContains:   []string{"23:00", "[Off]"},
 			wantNotContain: []string{"\033[", "[Extended]"},
 		},
+		{
+			name:           "weekend with color",
+			day:            6,
+			hour:           10,
+			noColor:        false,
+			wantContains:   []string{"\033[2m", "10:00", "[Day off]"},
+			wantNotContain: []string{"[Off]", "[Extended]"},
+		},
+		{
+			name:           "weekend without color",
+			day:            7,
+			hour:           10,
+			noColor:        true,
+			wantContains:   []string{"10:00", "[Day off]"},
+			wantNotContain: []string{"\033[", "[Off]", "[Extended]"},
+		},
+		{
+			name:           "friday off with custom work days",
+			hour:           10,
+			workDays:       types.Weekdays{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday},
+			noColor:        true,
+			wantContains:   []string{"10:00", "[Day off]"},
+			wantNotContain: []string{"[Off]", "[Extended]"},
+		},
+		{
+			name:           "sunday working with custom work days",
+			day:            7,
+			hour:           10,
+			workDays:       types.Weekdays{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday},
+			noColor:        true,
+			wantContains:   []string{"10:00"},
+			wantNotContain: []string{"[Day off]", "[Off]", "[Extended]"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			testTime := time.Date(2025, 12, 6, tt.hour, 0, 0, 0, time.UTC)
+			day := tt.day
+			if day == 0 {
+				day = 5 // Friday
+			}
+			testTime := time.Date(2025, 12, day, tt.hour, 0, 0, 0, time.UTC)
 			style := styles.NewStylesWithNoColor(tt.noColor)
-			result := getDisplayTime(testTime, style)
+			status := schedule.Classify(testTime, types.Colleague{WorkDays: tt.workDays})
+			result := getDisplayTime(testTime, status, style)
 
 			for _, want := range tt.wantContains {
 				if !strings.Contains(result, want) {
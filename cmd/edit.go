@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/matteo-gildone/teamtime/internals/types"
+	"github.com/spf13/cobra"
+)
+
+// editCmd represents the edit command
+var editCmd = &cobra.Command{
+	Use:   "edit <id|name>",
+	Short: "Edit a colleague",
+	Args:  cobra.ExactArgs(1),
+	RunE:  editFunc,
+}
+
+func init() {
+	editCmd.Flags().String("name", "", "new name")
+	editCmd.Flags().String("city", "", "new city")
+	editCmd.Flags().String("tz", "", "new time zone")
+	addScheduleFlags(editCmd)
+	editCmd.Flags().StringArray("tag", nil, "add a tag, repeatable or comma separated")
+	editCmd.Flags().StringArray("untag", nil, "remove a tag, repeatable or comma separated")
+	rootCmd.AddCommand(editCmd)
+}
+
+func editFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	var patch types.ColleaguePatch
+	for flag, field := range map[string]**string{
+		"name": &patch.Name,
+		"city": &patch.City,
+		"tz":   &patch.Timezone,
+	} {
+		if !cmd.Flags().Changed(flag) {
+			continue
+		}
+		value, err := cmd.Flags().GetString(flag)
+		if err != nil {
+			return fmt.Errorf("failed to get %s flag: %w", flag, err)
+		}
+		*field = &value
+	}
+
+	sched, err := readScheduleFlags(cmd)
+	if err != nil {
+		return fmt.Errorf("edit command: %w", err)
+	}
+	patch.WorkHours = sched.workHours
+	patch.ExtendedHours = sched.extendedHours
+	patch.WorkDays = sched.workDays
+
+	if patch.AddTags, err = readTagsFlag(cmd, "tag"); err != nil {
+		return fmt.Errorf("edit command: %w", err)
+	}
+	if patch.RemoveTags, err = readTagsFlag(cmd, "untag"); err != nil {
+		return fmt.Errorf("edit command: %w", err)
+	}
+
+	updated, err := svc.UpdateColleague(args[0], patch)
+	if err != nil {
+		return fmt.Errorf("edit command: %w", err)
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ %s was updated", updated.Name)))
+	return nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/roster"
+	"github.com/matteo-gildone/teamtime/internals/service"
+	"github.com/matteo-gildone/teamtime/internals/storage"
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/matteo-gildone/teamtime/internals/types"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export colleagues to CSV, JSON, YAML, Markdown or iCalendar",
+	Long: `Export every colleague to standard output, or to a file with --out.
+
+CSV, JSON and YAML keep every detail and can be read back with
+'teamtime import'. Markdown writes a table for wikis and docs, with the
+current UTC offset of each timezone, and ics an iCalendar file of working
+hours (see 'teamtime export ics'). The format is taken from --format, else
+from the --out extension, and defaults to JSON. vCard files can be imported
+but not exported. The file is only readable by you, as it holds personal
+details.
+
+Examples:
+  teamtime export --out team.csv
+  teamtime export --format markdown`,
+	Args: cobra.NoArgs,
+	RunE: exportFunc,
+}
+
+// exportIcsCmd represents the export ics command
+var exportIcsCmd = &cobra.Command{
+	Use:   "ics [query]",
+	Short: "Export working hours as an iCalendar file",
+	Long: `Export the working hours of the colleagues matching the query, or of
+everyone, as an iCalendar (RFC 5545) file that calendar apps can import or
+subscribe to.
+
+Each colleague gets a weekly event over their working hours on their working
+days, in their own timezone, so it stays right across daylight saving time
+changes. Events are marked free, so they do not block your own calendar.
+Queries work as in 'teamtime check'.
+
+A few timezones, such as Africa/Casablanca, change their clocks on dates that
+do not repeat every year. Their events end after the six years of changes
+written to the file; export again to extend them.
+
+Examples:
+  teamtime export ics --out team.ics
+  teamtime export ics tag:backend city:pune`,
+	RunE: exportIcsFunc,
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", "", fmt.Sprintf("file format: %s (default from the --out extension, else json)", strings.Join(roster.ExportFormats(), ", ")))
+	exportCmd.Flags().String("out", "", "write to this file instead of standard output")
+	exportIcsCmd.Flags().String("out", "", "write to this file instead of standard output")
+	exportCmd.AddCommand(exportIcsCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+func exportFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return fmt.Errorf("failed to get format flag: %w", err)
+	}
+
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("failed to get out flag: %w", err)
+	}
+
+	switch {
+	case format != "":
+	case out != "":
+		if format, err = roster.DetectFormat(out); err != nil {
+			return fmt.Errorf("export command: %w", err)
+		}
+	default:
+		format = roster.FormatJSON
+	}
+	if slices.Contains(roster.Formats(), format) && !slices.Contains(roster.ExportFormats(), format) {
+		return fmt.Errorf("export command: %s files can be imported but not exported, use --format with %s",
+			format, strings.Join(roster.ExportFormats(), ", "))
+	}
+
+	colleagues, err := svc.AllColleagues()
+	if err != nil {
+		return fmt.Errorf("export command: %w", err)
+	}
+
+	if err := writeExport(colleagues, format, out); err != nil {
+		return fmt.Errorf("export command: %w", err)
+	}
+	return nil
+}
+
+func exportIcsFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("failed to get out flag: %w", err)
+	}
+
+	query, err := service.ParseQuery(args...)
+	if err != nil {
+		return fmt.Errorf("export ics command: %w", err)
+	}
+
+	colleagues, err := svc.Search(query, time.Now())
+	if err != nil {
+		return fmt.Errorf("export ics command: %w", err)
+	}
+	if len(colleagues) == 0 && !query.IsEmpty() {
+		return fmt.Errorf("export ics command: %w: %s", errNoMatch, query)
+	}
+
+	if err := writeExport(colleagues, roster.FormatICS, out); err != nil {
+		return fmt.Errorf("export ics command: %w", err)
+	}
+	return nil
+}
+
+// writeExport writes colleagues in format to out, or to standard output when
+// out is empty. The file is replaced atomically and only readable by its
+// owner, as it holds personal details
+func writeExport(colleagues []types.Colleague, format, out string) error {
+	if out == "" {
+		return roster.Write(os.Stdout, colleagues, format, time.Now())
+	}
+
+	var buf bytes.Buffer
+	if err := roster.Write(&buf, colleagues, format, time.Now()); err != nil {
+		return err
+	}
+	if err := storage.WriteFileAtomic(out, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ exported %d colleagues to %s", len(colleagues), out)))
+	return nil
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/matteo-gildone/teamtime/internals/geo"
+	"github.com/matteo-gildone/teamtime/internals/roster"
+	"github.com/matteo-gildone/teamtime/internals/service"
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/matteo-gildone/teamtime/internals/types"
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import colleagues from a CSV, JSON, YAML or vCard file",
+	Long: `Import colleagues from a CSV, JSON, YAML or vCard file, or from standard
+input with '-'. The format is detected from the file extension unless --format
+is given.
+
+Each colleague has a name, city and timezone, and optionally an id,
+work_hours (9-17), extended_hours (7-20), work_days (mon-fri) and tags
+(comma separated). CSV files name these fields in their first line. vCards
+give the name (FN), the city (locality of the work address, or of the first
+address) and the timezone (TZ).
+
+Colleagues without a timezone get the one of their city, looked up in a list
+of cities that works offline, otherwise the --default-tz timezone. Failing
+that, teamtime asks for it when run in a terminal.
+
+Every entry is validated before anything is written, and all problems are
+reported with their line numbers. By default the import fails if a colleague
+with the same name already exists; --merge skips them, --merge=update
+updates them, keeping their ids, and --replace replaces the whole list.`,
+	Args: cobra.ExactArgs(1),
+	RunE: importFunc,
+}
+
+const (
+	mergeSkip   = "skip"
+	mergeUpdate = "update"
+)
+
+func init() {
+	importCmd.Flags().StringP("format", "f", "", fmt.Sprintf("file format: %s (default from the file extension)", strings.Join(roster.Formats(), ", ")))
+	importCmd.Flags().String("merge", "", "merge with existing colleagues of the same name: skip or update")
+	importCmd.Flags().Lookup("merge").NoOptDefVal = mergeSkip
+	importCmd.Flags().Bool("replace", false, "replace every existing colleague")
+	importCmd.Flags().String("default-tz", "", "timezone of the colleagues without one whose city is unknown or ambiguous")
+	rootCmd.AddCommand(importCmd)
+}
+
+func importFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	mode, err := readImportMode(cmd)
+	if err != nil {
+		return fmt.Errorf("import command: %w", err)
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return fmt.Errorf("failed to get format flag: %w", err)
+	}
+
+	defaultTZ, err := cmd.Flags().GetString("default-tz")
+	if err != nil {
+		return fmt.Errorf("failed to get default-tz flag: %w", err)
+	}
+	if defaultTZ != "" {
+		if _, err := loadLocation(defaultTZ); err != nil {
+			return fmt.Errorf("import command: %w", err)
+		}
+	}
+
+	path := args[0]
+	if format == "" {
+		if path == "-" {
+			return fmt.Errorf("import command: --format is required when reading standard input")
+		}
+		if format, err = roster.DetectFormat(path); err != nil {
+			return fmt.Errorf("import command: %w", err)
+		}
+	}
+
+	resolve := timezoneResolver(defaultTZ, path != "-" && isInteractive())
+	entries, err := readRoster(path, format, roster.WithTimezoneResolver(resolve))
+	if err != nil {
+		if defaultTZ == "" && errors.Is(err, types.ErrMissingTimezone) {
+			return fmt.Errorf("%w\nuse --default-tz to give a timezone to the colleagues without one", importError(path, err))
+		}
+		return importError(path, err)
+	}
+
+	result, err := svc.ImportColleagues(entries, mode)
+	var lineErr *roster.LineError
+	if errors.As(err, &lineErr) {
+		return importError(path, err)
+	}
+	if errors.Is(err, service.ErrAlreadyExists) {
+		return fmt.Errorf("import command: %w, use --merge to skip them, --merge=update to update them or --replace", err)
+	}
+	if err != nil {
+		return fmt.Errorf("import command: %w", err)
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ imported %s", describeImport(result))))
+	return nil
+}
+
+func readImportMode(cmd *cobra.Command) (service.ImportMode, error) {
+	merge, err := cmd.Flags().GetString("merge")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get merge flag: %w", err)
+	}
+	replace, err := cmd.Flags().GetBool("replace")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get replace flag: %w", err)
+	}
+
+	switch {
+	case replace && cmd.Flags().Changed("merge"):
+		return 0, fmt.Errorf("use either --merge or --replace, not both")
+	case replace:
+		return service.ImportReplace, nil
+	case merge == mergeSkip:
+		return service.ImportSkip, nil
+	case merge == mergeUpdate:
+		return service.ImportUpdate, nil
+	case merge == "":
+		return service.ImportAdd, nil
+	default:
+		return 0, fmt.Errorf("unknown merge mode %q, use %s or %s", merge, mergeSkip, mergeUpdate)
+	}
+}
+
+// readRoster reads the colleagues in path, or in standard input for "-"
+func readRoster(path, format string, opts ...roster.ReadOption) ([]roster.Entry, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return roster.Read(r, format, opts...)
+}
+
+// timezoneResolver finds the timezone of a colleague imported without one:
+// the timezone of their city, else defaultTZ, else, when prompt is set, the
+// one picked by the user
+func timezoneResolver(defaultTZ string, prompt bool) roster.TimezoneResolver {
+	return func(name, city string) (string, error) {
+		places := geo.Lookup(city)
+		if tz, ok := geo.Timezone(places); ok {
+			return tz, nil
+		}
+		if defaultTZ != "" {
+			return defaultTZ, nil
+		}
+		if !prompt {
+			return "", nil
+		}
+		return askTimezone(city, fmt.Sprintf("Timezone of %s in %s", name, city), places), nil
+	}
+}
+
+// importError lists every invalid entry on its own line. roster.Read joins
+// them into one error
+func importError(path string, err error) error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return fmt.Errorf("import command: %s: %w", path, err)
+	}
+
+	errs := joined.Unwrap()
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = "  " + e.Error()
+	}
+	noun := "entries"
+	if len(errs) == 1 {
+		noun = "entry"
+	}
+	return fmt.Errorf("import command: %s has %d invalid %s, nothing was imported:\n%s", path, len(errs), noun, strings.Join(lines, "\n"))
+}
+
+func describeImport(r service.ImportResult) string {
+	parts := []string{fmt.Sprintf("%d added", r.Added)}
+	if r.Updated > 0 {
+		parts = append(parts, fmt.Sprintf("%d updated", r.Updated))
+	}
+	if r.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", r.Skipped))
+	}
+	if r.Removed > 0 {
+		parts = append(parts, fmt.Sprintf("%d replaced", r.Removed))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/matteo-gildone/teamtime/internals/storage"
 	"github.com/matteo-gildone/teamtime/internals/styles"
@@ -15,7 +14,7 @@ var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialise project",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := initFunc()
+		err := initFunc(cmd)
 		if err != nil {
 			return fmt.Errorf("init command:%w", err)
 		}
@@ -27,13 +26,8 @@ func init() {
 	rootCmd.AddCommand(initCmd)
 }
 
-func initFunc() error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get user home directory %w", err)
-	}
-	m, err := storage.NewManager(homeDir)
-
+func initFunc(cmd *cobra.Command) error {
+	m, err := newManager(cmd)
 	if err != nil {
 		return fmt.Errorf("init command - %w", err)
 	}
@@ -49,7 +43,11 @@ func initFunc() error {
 	cl := types.ColleagueList{}
 
 	if err = m.Save(&cl); err != nil {
-		return fmt.Errorf("failed create 'colleagues.json' %w", err)
+		return fmt.Errorf("failed create '%s' %w", m.GetRelativeFilePath(), err)
+	}
+
+	if err = defaultToFirstProfile(cmd); err != nil {
+		return err
 	}
 
 	fmt.Println(styles.NewStyles().Cyan().Bold().Render(`
@@ -64,3 +62,26 @@ func initFunc() error {
 	fmt.Println()
 	return nil
 }
+
+// defaultToFirstProfile makes the profile just created the default one when it
+// is the only profile, so that later commands use it without --profile
+func defaultToFirstProfile(cmd *cobra.Command) error {
+	if configFlag, _ := cmd.Flags().GetString("config"); configFlag != "" {
+		return nil
+	}
+
+	profiles, err := newProfiles(cmd)
+	if err != nil {
+		return err
+	}
+
+	names, err := profiles.List()
+	if err != nil {
+		return err
+	}
+
+	if len(names) != 1 || names[0] == storage.DefaultProfile {
+		return nil
+	}
+	return profiles.SetDefault(names[0])
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/report"
+	"github.com/matteo-gildone/teamtime/internals/schedule"
+	"github.com/matteo-gildone/teamtime/internals/service"
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/matteo-gildone/teamtime/internals/types"
+	"github.com/spf13/cobra"
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan [query...]",
+	Short: "Find meeting windows where everyone is in working hours",
+	Long: `Find meeting windows where everyone is in working hours.
+
+Each argument selects participants with the query language of check, e.g.
+priya or "tag:backend city:berlin", and the meeting is for everyone selected
+by any of them. Without arguments, or with --all, it is for everyone.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: planFunc,
+}
+
+func init() {
+	planCmd.Flags().StringP("date", "d", "", "meeting date as YYYY-MM-DD (default today)")
+	planCmd.Flags().Duration("duration", 30*time.Minute, "meeting duration, e.g. 30m or 1h30m")
+	planCmd.Flags().String("tz", "", "timezone used to show the windows (default local)")
+	planCmd.Flags().IntP("limit", "n", 5, "maximum number of windows to show")
+	planCmd.Flags().BoolP("all", "a", false, "plan for everyone, the same as no query")
+	rootCmd.AddCommand(planCmd)
+}
+
+func planFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get colleague service: %w", err)
+	}
+
+	tz, err := cmd.Flags().GetString("tz")
+	if err != nil {
+		return fmt.Errorf("failed to get tz flag: %w", err)
+	}
+
+	loc, err := loadLocation(tz)
+	if err != nil {
+		return err
+	}
+
+	date, err := cmd.Flags().GetString("date")
+	if err != nil {
+		return fmt.Errorf("failed to get date flag: %w", err)
+	}
+
+	day := time.Now().In(loc)
+	if date != "" {
+		day, err = time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	duration, err := cmd.Flags().GetDuration("duration")
+	if err != nil {
+		return fmt.Errorf("failed to get duration flag: %w", err)
+	}
+
+	limit, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		return fmt.Errorf("failed to get limit flag: %w", err)
+	}
+
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return fmt.Errorf("failed to get all flag: %w", err)
+	}
+
+	participants, err := getParticipants(svc, args, all, time.Now())
+	if err != nil {
+		return fmt.Errorf("plan command: %w", err)
+	}
+
+	windows, err := schedule.FindWindows(participants, day, duration)
+	if err != nil {
+		return fmt.Errorf("plan command: %w", err)
+	}
+
+	renderPlan(windows, participants, day, duration, limit)
+	return nil
+}
+
+func loadLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	return loc, nil
+}
+
+// getParticipants returns the colleagues matching any of args, each a query
+// such as priya or "tag:backend city:berlin" with statuses computed at now
+// and names matched loosely, keeping each colleague once. No arguments select everyone, as does all,
+// which cannot be combined with arguments. A query matching nobody is an
+// error suggesting the closest names
+func getParticipants(svc *service.ColleagueService, args []string, all bool, now time.Time) (types.ColleagueList, error) {
+	if all && len(args) > 0 {
+		return nil, fmt.Errorf("use either --all or names, not both")
+	}
+
+	var queries []service.Query
+	for _, arg := range args {
+		q, err := service.ParseQuery(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !q.IsEmpty() {
+			queries = append(queries, q)
+		}
+	}
+
+	if len(queries) == 0 {
+		return svc.AllColleagues()
+	}
+
+	var participants types.ColleagueList
+	seen := make(map[string]bool)
+	for _, q := range queries {
+		colleagues, err := svc.SearchFuzzy(q, now)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(colleagues) == 0 {
+			return nil, noMatchError(svc, q)
+		}
+
+		for _, c := range colleagues {
+			if seen[c.ID] {
+				continue
+			}
+			seen[c.ID] = true
+			participants = append(participants, c)
+		}
+	}
+
+	return participants, nil
+}
+
+// noMatchError reports that q matched nobody, suggesting the names closest to
+// its name terms
+func noMatchError(svc *service.ColleagueService, q service.Query) error {
+	names := q.Names()
+	if len(names) == 0 {
+		return fmt.Errorf("%w: %s", errNoMatch, q)
+	}
+
+	suggestions, err := svc.Suggest(strings.Join(names, " "))
+	if err != nil {
+		return err
+	}
+	if len(suggestions) == 0 {
+		return fmt.Errorf("%w: %s", errNoMatch, q)
+	}
+	return fmt.Errorf("%w: %s, did you mean %s?", errNoMatch, q, service.DidYouMean(suggestions))
+}
+
+func renderPlan(windows []schedule.Window, participants types.ColleagueList, day time.Time, duration time.Duration, limit int) {
+	plainStyle := styles.NewStyles()
+	heading := plainStyle.Bold()
+	dimStyle := plainStyle.Dim()
+
+	fmt.Println()
+	fmt.Println(heading.Render(fmt.Sprintf("%s meeting on %s (%s) for %d colleagues",
+		report.FormatDuration(duration), day.Format("Mon 02 Jan 2006"), day.Location(), len(participants))))
+	fmt.Println()
+
+	if len(windows) > limit && limit > 0 {
+		windows = windows[:limit]
+	}
+
+	for idx, w := range windows {
+		span := fmt.Sprintf("%-4s %s - %s", fmt.Sprintf("%d.", idx+1), w.Start.Format("15:04"), w.End.Format("15:04"))
+		if w.End.YearDay() != w.Start.YearDay() {
+			span += " (+1)"
+		}
+
+		switch {
+		case w.Perfect():
+			fmt.Printf("%s  %s\n", plainStyle.Bold().Cyan().Render(fmt.Sprintf("%-20s", span)), "everyone in working hours")
+		case len(w.Off) == 0:
+			fmt.Printf("%s  %s\n", plainStyle.Bold().Yellow().Render(fmt.Sprintf("%-20s", span)), describeWindow(w))
+		default:
+			fmt.Printf("%s  %s\n", plainStyle.Bold().Red().Render(fmt.Sprintf("%-20s", span)), describeWindow(w))
+		}
+
+		for _, c := range participants {
+			spans, err := schedule.Spans(w.Start, w.End, c)
+			if err != nil || len(spans) == 0 {
+				continue
+			}
+			fmt.Println(dimStyle.Render(fmt.Sprintf("       %-20s %s (%s)", c.Name, spans[0].Start.Format("15:04 Mon"), describeSpans(spans))))
+		}
+	}
+	fmt.Println()
+}
+
+func describeWindow(w schedule.Window) string {
+	var parts []string
+	if len(w.Extended) > 0 {
+		parts = append(parts, "extended hours: "+joinNames(w.Extended))
+	}
+	if len(w.Off) > 0 {
+		parts = append(parts, "off hours: "+joinNames(w.Off))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeSpans tells how a colleague's availability changes over a window,
+// e.g. "work until 17:00, then extended"
+func describeSpans(spans []schedule.Span) string {
+	parts := make([]string, len(spans))
+	for i, span := range spans {
+		parts[i] = string(span.Status)
+		if i < len(spans)-1 {
+			parts[i] += " until " + span.End.Format("15:04")
+		}
+	}
+	return strings.Join(parts, ", then ")
+}
+
+func joinNames(colleagues []types.Colleague) string {
+	names := make([]string, len(colleagues))
+	for i, c := range colleagues {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
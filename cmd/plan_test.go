@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/schedule"
+	"github.com/matteo-gildone/teamtime/internals/service"
+	"github.com/matteo-gildone/teamtime/internals/storage"
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func TestGetParticipants(t *testing.T) {
+	m, err := storage.NewManagerForFile("", filepath.Join(t.TempDir(), "colleagues.json"))
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	svc := service.NewColleagueService(m)
+	for _, c := range []struct{ name, city, tz, tag string }{
+		{"Alice", "London", "Europe/London", "backend"},
+		{"Priya", "Pune", "Asia/Kolkata", "backend"},
+		{"All", "Oslo", "Europe/Oslo", "frontend"},
+	} {
+		if _, err := svc.AddColleague(c.name, c.city, c.tz, types.WithTags(types.Tags{c.tag})); err != nil {
+			t.Fatalf("failed to add %s: %v", c.name, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		all     bool
+		want    string
+		wantErr error
+	}{
+		{name: "everyone without arguments", want: "Alice,Priya,All"},
+		{name: "everyone with all", all: true, want: "Alice,Priya,All"},
+		{name: "all is a name", args: []string{"all"}, want: "All"},
+		{name: "any of the queries", args: []string{"priya", "city:oslo"}, want: "Priya,All"},
+		{name: "terms of a query", args: []string{"tag:backend city:london"}, want: "Alice"},
+		{name: "each colleague once", args: []string{"tag:backend", "alice"}, want: "Alice,Priya"},
+		{name: "close names", args: []string{"prya", "alce"}, want: "Priya,Alice"},
+		{name: "query matching nobody", args: []string{"alice", "pryia"}, wantErr: errNoMatch},
+		{name: "invalid query", args: []string{"team:core"}, wantErr: service.ErrInvalidQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getParticipants(svc, tt.args, tt.all, time.Now())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if names := joinNames(got); strings.ReplaceAll(names, ", ", ",") != tt.want {
+				t.Errorf("got %s, want %s", names, tt.want)
+			}
+		})
+	}
+
+	t.Run("all with arguments", func(t *testing.T) {
+		if _, err := getParticipants(svc, []string{"alice"}, true, time.Now()); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("suggests close names", func(t *testing.T) {
+		_, err := getParticipants(svc, []string{"pryia"}, false, time.Now())
+		if err == nil || !strings.Contains(err.Error(), `did you mean "Priya"`) {
+			t.Errorf("expected a suggestion, got %v", err)
+		}
+	})
+}
+
+func TestDescribeSpans(t *testing.T) {
+	friday := time.Date(2025, 12, 5, 9, 30, 0, 0, time.UTC)
+	kolkata := types.Colleague{Timezone: "Asia/Kolkata"}
+
+	tests := []struct {
+		name string
+		end  time.Time
+		want string
+	}{
+		{name: "one status", end: friday.Add(time.Hour), want: "work"},
+		{name: "crossing the end of work", end: friday.Add(6 * time.Hour), want: "work until 17:00, then extended until 20:00, then off"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans, err := schedule.Spans(friday, tt.end, kolkata)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := describeSpans(spans); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/matteo-gildone/teamtime/internals/storage"
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/spf13/cobra"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named rosters",
+	Long: `Manage named rosters, e.g. one per team. The default profile lives in
+colleagues.json and every other one in profiles/<name>.json next to it. Select a
+profile for a single command with --profile, or persist it with 'profile use'.`,
+	// Profiles are managed without loading any roster
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+// profileListCmd represents the profile list command
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles, marking the default one",
+	Args:  cobra.NoArgs,
+	RunE:  profileListFunc,
+}
+
+// profileCreateCmd represents the profile create command
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create an empty profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  profileCreateFunc,
+}
+
+// profileUseCmd represents the profile use command
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a profile the default one",
+	Args:  cobra.ExactArgs(1),
+	RunE:  profileUseFunc,
+}
+
+// profileDeleteCmd represents the profile delete command
+var profileDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a profile, keeping a backup of it",
+	Args:  cobra.ExactArgs(1),
+	RunE:  profileDeleteFunc,
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd, profileCreateCmd, profileUseCmd, profileDeleteCmd)
+	rootCmd.AddCommand(profileCmd)
+}
+
+func profileListFunc(cmd *cobra.Command, args []string) error {
+	profiles, err := newProfiles(cmd)
+	if err != nil {
+		return fmt.Errorf("profile list command: %w", err)
+	}
+
+	names, err := profiles.List()
+	if err != nil {
+		return fmt.Errorf("profile list command: %w", err)
+	}
+
+	if len(names) == 0 {
+		fmt.Println(styles.NewStyles().Cyan().Render("no profiles found, run 'teamtime init'"))
+		return nil
+	}
+
+	current, err := profiles.Default()
+	if err != nil {
+		return fmt.Errorf("profile list command: %w", err)
+	}
+
+	for _, name := range names {
+		if name == current {
+			fmt.Println(styles.NewStyles().Green().Render("* " + name))
+			continue
+		}
+		fmt.Println("  " + name)
+	}
+	return nil
+}
+
+func profileCreateFunc(cmd *cobra.Command, args []string) error {
+	name, err := storage.NormalizeProfile(args[0])
+	if err != nil {
+		return fmt.Errorf("profile create command: %w", err)
+	}
+
+	profiles, err := newProfiles(cmd)
+	if err != nil {
+		return fmt.Errorf("profile create command: %w", err)
+	}
+
+	m, err := profiles.Create(name)
+	if err != nil {
+		return fmt.Errorf("profile create command: %w", err)
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ profile %s was created in %s", name, m.GetRelativeFilePath())))
+	return nil
+}
+
+func profileUseFunc(cmd *cobra.Command, args []string) error {
+	name, err := storage.NormalizeProfile(args[0])
+	if err != nil {
+		return fmt.Errorf("profile use command: %w", err)
+	}
+
+	profiles, err := newProfiles(cmd)
+	if err != nil {
+		return fmt.Errorf("profile use command: %w", err)
+	}
+
+	if err := profiles.SetDefault(name); err != nil {
+		return fmt.Errorf("profile use command: %w", err)
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ now using profile %s", name)))
+	return nil
+}
+
+func profileDeleteFunc(cmd *cobra.Command, args []string) error {
+	name, err := storage.NormalizeProfile(args[0])
+	if err != nil {
+		return fmt.Errorf("profile delete command: %w", err)
+	}
+
+	profiles, err := newProfiles(cmd)
+	if err != nil {
+		return fmt.Errorf("profile delete command: %w", err)
+	}
+
+	if err := profiles.Delete(name); err != nil {
+		return fmt.Errorf("profile delete command: %w", err)
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ profile %s was deleted, its last snapshot is kept in backups", name)))
+	return nil
+}
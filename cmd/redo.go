@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/matteo-gildone/teamtime/internals/storage"
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/spf13/cobra"
+)
+
+// redoCmd represents the redo command
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Redo the last undone change",
+	Long: `Redo the last change reversed by 'teamtime undo'. Making a new change with add,
+remove, edit or import discards anything left to redo.`,
+	Args: cobra.NoArgs,
+	RunE: redoFunc,
+}
+
+func redoFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	entry, err := svc.Redo()
+	if errors.Is(err, storage.ErrNothingToRedo) {
+		fmt.Println(styles.NewStyles().Cyan().Render("nothing to redo"))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("redo command: %w", err)
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ redid %s", entry.Summary())))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(redoCmd)
+}
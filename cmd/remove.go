@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/matteo-gildone/teamtime/internals/styles"
 	"github.com/spf13/cobra"
@@ -10,23 +9,19 @@ import (
 
 // removeCmd represents the remove command
 var removeCmd = &cobra.Command{
-	Use:   "remove",
+	Use:   "remove <id|name>",
 	Short: "Remove colleague",
 	Args:  cobra.ExactArgs(1),
 	RunE:  removeFunc,
 }
 
 func removeFunc(cmd *cobra.Command, args []string) error {
-	idx, err := strconv.Atoi(args[0])
-	if err != nil {
-		return fmt.Errorf("index must be a number %w", err)
-	}
 	svc, err := GetColleaguesService(cmd.Context())
 	if err != nil {
 		return err
 	}
 
-	removed, err := svc.RemoveColleague(idx)
+	removed, err := svc.RemoveColleague(args[0])
 	if err != nil {
 		return fmt.Errorf("remove command: %w", err)
 	}
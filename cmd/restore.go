@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/spf13/cobra"
+)
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <snapshot|#>",
+	Short: "Restore the colleagues file from a backup",
+	Long: `Restore the colleagues file from a backup listed by 'teamtime backup list',
+given either its snapshot name or its number. The snapshot is validated before
+it replaces the current file, which is itself backed up first.`,
+	Args: cobra.ExactArgs(1),
+	RunE: restoreFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
+
+func restoreFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	backup, restored, err := svc.Restore(args[0])
+	if err != nil {
+		return fmt.Errorf("restore command: %w", err)
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ restored %d colleagues from %s", len(restored), backup.Name)))
+	return nil
+}
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/matteo-gildone/teamtime/internals/service"
 	"github.com/matteo-gildone/teamtime/internals/storage"
+	"github.com/matteo-gildone/teamtime/internals/styles"
 	"github.com/spf13/cobra"
 )
 
@@ -26,18 +31,16 @@ var rootCmd = &cobra.Command{
 			return nil
 		}
 
-		homeDir, err := os.UserHomeDir()
+		m, err := newManager(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to get user home directory %w", err)
-		}
-		m, err := storage.NewManager(homeDir)
-
-		if err != nil {
-			return fmt.Errorf("failed to create manager %w", err)
+			return err
 		}
 
 		if !m.Exists() {
-			return fmt.Errorf("'colleagues.json' not found, run 'teamtime init'")
+			if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+				return fmt.Errorf("profile %s not found, run 'teamtime profile create %s'", profile, profile)
+			}
+			return fmt.Errorf("'%s' not found, run 'teamtime init'", m.GetRelativeFilePath())
 		}
 
 		svc := service.NewColleagueService(m)
@@ -51,15 +54,191 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	rootCmd.PersistentFlags().String("config", "", "path to the colleagues file (overrides $TEAMTIME_HOME and $XDG_CONFIG_HOME)")
+	rootCmd.PersistentFlags().StringP("profile", "p", "", "profile to use instead of the default one")
+}
+
+// newManager returns a manager for the colleagues file given with --config,
+// or else for the profile given with --profile or the default profile
+func newManager(cmd *cobra.Command) (*storage.Manager, error) {
+	configFlag, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, err
+	}
+	profileFlag, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return nil, err
+	}
+
+	if configFlag != "" {
+		if profileFlag != "" {
+			return nil, fmt.Errorf("--profile cannot be used with --config")
+		}
+		homeDir, _ := os.UserHomeDir()
+		// The lock, journal and backups go to the teamtime directory rather
+		// than next to the file, when there is one
+		var m *storage.Manager
+		if loc, locErr := storage.Locate("", homeDir, os.Getenv); locErr == nil {
+			m, err = storage.NewManagerForExternalFile(homeDir, configFlag, filepath.Dir(loc.FilePath))
+		} else {
+			m, err = storage.NewManagerForFile(homeDir, configFlag)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create manager %w", err)
+		}
+		return m, nil
+	}
+
+	profiles, err := newProfiles(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := selectedProfile(cmd, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := profiles.Manager(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manager %w", err)
+	}
+	return m, nil
+}
+
+// newProfiles resolves the directory holding the colleagues file and returns
+// the profiles kept in it, first offering to move a legacy ~/.teamtime to the
+// XDG location
+func newProfiles(cmd *cobra.Command) (*storage.Profiles, error) {
+	configFlag, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, err
+	}
+	if configFlag != "" {
+		return nil, fmt.Errorf("profiles cannot be used with --config")
+	}
+
+	// The home directory is only required by the legacy location
+	homeDir, _ := os.UserHomeDir()
+
+	loc, err := storage.Locate("", homeDir, os.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate colleagues file: %w", err)
+	}
+
+	if loc.MigrateTo != "" && isInteractive() {
+		loc, err = offerMigration(loc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return storage.NewProfiles(homeDir, filepath.Dir(loc.FilePath)), nil
+}
+
+// selectedProfile returns the profile given with --profile, or else the
+// persisted default profile
+func selectedProfile(cmd *cobra.Command, profiles *storage.Profiles) (string, error) {
+	name, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		return storage.NormalizeProfile(name)
+	}
+	return profiles.Default()
+}
+
+// offerMigration asks whether to move the legacy directory to the XDG location
+// and returns where the colleagues file lives afterwards. The answer is
+// remembered either way
+func offerMigration(loc storage.Location) (storage.Location, error) {
+	question := fmt.Sprintf("Move %s to %s? [y/N] ", filepath.Dir(loc.FilePath), filepath.Dir(loc.MigrateTo))
+	if !confirm(question) {
+		if err := storage.DeclineMigration(loc); err != nil {
+			return storage.Location{}, err
+		}
+		return loc, nil
+	}
+
+	if err := storage.MigrateLegacy(loc); err != nil {
+		return storage.Location{}, fmt.Errorf("failed to migrate colleagues file: %w", err)
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Fprintln(os.Stderr, successStyle.Render(fmt.Sprintf("✓ moved to %s", filepath.Dir(loc.MigrateTo))))
+	return storage.Location{FilePath: loc.MigrateTo, Source: "$" + storage.EnvXDGConfigHome}, nil
+}
+
+// isInteractive reports whether stdin is a terminal, so that scripts and CI
+// are never blocked on a question
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// The null device is a character device too
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
+// stdin is shared by the prompts, so that input buffered by one is not lost
+// to the next
+var stdin = bufio.NewReader(os.Stdin)
+
+// ask prints question to stderr and returns the answer, trimmed. It returns ""
+// when standard input is closed
+func ask(question string) string {
+	fmt.Fprint(os.Stderr, question)
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return ""
+	}
+	return strings.TrimSpace(answer)
+}
+
+// confirm prints question to stderr and reports whether the answer was yes
+func confirm(question string) bool {
+	switch strings.ToLower(ask(question)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// errNoMatch reports that a command found nobody matching its query
+var errNoMatch = errors.New("no colleague matches")
+
+// exitError makes Execute exit with a specific status
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
 func GetColleaguesService(ctx context.Context) (*service.ColleagueService, error) {
 	val := ctx.Value(serviceKey)
 	if val == nil {
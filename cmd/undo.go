@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/matteo-gildone/teamtime/internals/storage"
+	"github.com/matteo-gildone/teamtime/internals/styles"
+	"github.com/spf13/cobra"
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last add, remove, edit or import",
+	Long: `Undo the last add, remove, edit or import. Changes are recorded in a journal
+next to the colleagues file, so repeated undos walk further back, up to the
+last 10 changes. Undo refuses to run if the colleagues file was changed outside
+of teamtime since it was recorded.`,
+	Args: cobra.NoArgs,
+	RunE: undoFunc,
+}
+
+func undoFunc(cmd *cobra.Command, args []string) error {
+	svc, err := GetColleaguesService(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	entry, err := svc.Undo()
+	if errors.Is(err, storage.ErrNothingToUndo) {
+		fmt.Println(styles.NewStyles().Cyan().Render("nothing to undo"))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("undo command: %w", err)
+	}
+
+	successStyle := styles.NewStyles().Green()
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ undid %s", entry.Summary())))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
@@ -0,0 +1,53 @@
+package fold
+
+import "strings"
+
+// Text lowercases s and strips the accents of Latin letters, so that "Lúcio"
+// and "lucio" compare equal
+func Text(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if folded, ok := accentFolds[r]; ok {
+			sb.WriteString(folded)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+var accentFolds = buildAccentFolds(map[string]string{
+	"a":  "àáâãäåāăą",
+	"ae": "æ",
+	"c":  "çćĉċč",
+	"d":  "ďđð",
+	"e":  "èéêëēĕėęě",
+	"g":  "ĝğġģ",
+	"h":  "ĥħ",
+	"i":  "ìíîïĩīĭįı",
+	"j":  "ĵ",
+	"k":  "ķ",
+	"l":  "ĺļľŀł",
+	"n":  "ñńņňŉ",
+	"o":  "òóôõöøōŏő",
+	"oe": "œ",
+	"r":  "ŕŗř",
+	"s":  "śŝşšș",
+	"ss": "ß",
+	"t":  "ţťŧț",
+	"th": "þ",
+	"u":  "ùúûüũūŭůűų",
+	"w":  "ŵ",
+	"y":  "ýÿŷ",
+	"z":  "źżž",
+})
+
+func buildAccentFolds(groups map[string]string) map[rune]string {
+	folds := make(map[rune]string)
+	for base, accented := range groups {
+		for _, r := range accented {
+			folds[r] = base
+		}
+	}
+	return folds
+}
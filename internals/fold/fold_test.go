@@ -0,0 +1,20 @@
+package fold
+
+import "testing"
+
+func TestText(t *testing.T) {
+	tests := map[string]string{
+		"Lúcio":     "lucio",
+		"São Paulo": "sao paulo",
+		"Łódź":      "lodz",
+		"Straße":    "strasse",
+		"Zoë":       "zoe",
+		"東京":        "東京",
+	}
+
+	for in, want := range tests {
+		if got := Text(in); got != want {
+			t.Errorf("Text(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package geo
+
+import (
+	"cmp"
+	_ "embed"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/matteo-gildone/teamtime/internals/fold"
+)
+
+// citiesTab lists cities with their region, country, coordinates and
+// timezone. See the comment at its top for the columns
+//
+//go:embed cities.tab
+var citiesTab string
+
+// zoneTab is the zone.tab file of the tz database, which lists a timezone for
+// every area of every country along with the city it is named after
+//
+//go:embed zone.tab
+var zoneTab string
+
+// iso3166Tab is the iso3166.tab file of the tz database, which names the
+// countries
+//
+//go:embed iso3166.tab
+var iso3166Tab string
+
+// countryAliases are common names of countries that iso3166.tab spells
+// differently
+var countryAliases = map[string]string{
+	"usa":                      "US",
+	"united states of america": "US",
+	"uk":                       "GB",
+	"united kingdom":           "GB",
+	"great britain":            "GB",
+	"england":                  "GB",
+	"scotland":                 "GB",
+	"wales":                    "GB",
+	"czechia":                  "CZ",
+	"turkiye":                  "TR",
+	"holland":                  "NL",
+	"uae":                      "AE",
+	"south korea":              "KR",
+	"north korea":              "KP",
+}
+
+// Place is a city with the timezone used there
+type Place struct {
+	// Name is the usual English name, e.g. "Portland"
+	Name string
+	// Aliases are other names of the city, e.g. "Bombay" for Mumbai
+	Aliases []string
+	// Region is the state or province, when it helps to tell cities apart
+	Region string
+	// RegionCode is the short form of Region, e.g. "OR" for Oregon
+	RegionCode string
+	// Country is the ISO 3166 country code, e.g. "US"
+	Country string
+	// Latitude and Longitude are in decimal degrees, north and east positive
+	Latitude  float64
+	Longitude float64
+	// Timezone is the IANA name, e.g. America/Los_Angeles
+	Timezone string
+}
+
+// String describes the place with its region and country, e.g.
+// "Portland, Oregon, US"
+func (p Place) String() string {
+	parts := []string{p.Name}
+	if p.Region != "" && p.Region != p.Name {
+		parts = append(parts, p.Region)
+	}
+	return strings.Join(append(parts, p.Country), ", ")
+}
+
+// names returns the name and aliases of p, normalized
+func (p Place) names() []string {
+	names := []string{normalizeCity(p.Name)}
+	for _, alias := range p.Aliases {
+		names = append(names, normalizeCity(alias))
+	}
+	return names
+}
+
+// namesTimezone returns 1 when the timezone of p is named after it, else 0
+func (p Place) namesTimezone() int {
+	city := p.Timezone[strings.LastIndex(p.Timezone, "/")+1:]
+	if normalizeCity(city) == normalizeCity(p.Name) {
+		return 1
+	}
+	return 0
+}
+
+// matchesQualifier reports whether q, normalized, names the region or the
+// country of p, either by name or by code
+func (p Place) matchesQualifier(q string) bool {
+	switch q {
+	case normalizeCity(p.Region), normalizeCity(p.RegionCode), normalizeCity(p.Country):
+		return q != ""
+	}
+	if countryAliases[q] == p.Country {
+		return true
+	}
+	load()
+	return slices.Contains(countries[p.Country], q)
+}
+
+var (
+	placesOnce sync.Once
+	places     []Place
+	// countries maps country codes to the normalized names of the country
+	countries map[string][]string
+)
+
+func load() {
+	placesOnce.Do(func() {
+		places = parseCities(citiesTab)
+
+		known := make(map[string]bool)
+		for _, p := range places {
+			for _, name := range p.names() {
+				known[name+"\t"+p.Timezone] = true
+			}
+		}
+		for _, p := range parseZones(zoneTab) {
+			if !known[normalizeCity(p.Name)+"\t"+p.Timezone] {
+				places = append(places, p)
+			}
+		}
+
+		countries = parseCountries(iso3166Tab)
+	})
+}
+
+// Places returns every city of the gazetteer followed by the cities listed in
+// zone.tab that the gazetteer lacks
+func Places() []Place {
+	load()
+	return places
+}
+
+// Lookup returns the places named query, ignoring case and accents, so that
+// "são paulo" finds São Paulo. The name may be followed by a comma and a
+// region or country, by name or by code, to tell cities of the same name
+// apart, e.g. "Portland, OR" or "Valencia, Spain"
+func Lookup(query string) []Place {
+	parts := strings.Split(query, ",")
+	name := normalizeCity(parts[0])
+	if name == "" {
+		return nil
+	}
+
+	var qualifiers []string
+	for _, part := range parts[1:] {
+		if q := normalizeCity(part); q != "" {
+			qualifiers = append(qualifiers, q)
+		}
+	}
+
+	var found []Place
+	for _, p := range Places() {
+		if !slices.Contains(p.names(), name) {
+			continue
+		}
+
+		matches := true
+		for _, q := range qualifiers {
+			matches = matches && p.matchesQualifier(q)
+		}
+		if matches {
+			found = append(found, p)
+		}
+	}
+
+	// the city a timezone is named after, such as London for Europe/London,
+	// is the one most people mean
+	slices.SortStableFunc(found, func(a, b Place) int {
+		return cmp.Compare(b.namesTimezone(), a.namesTimezone())
+	})
+	return found
+}
+
+// Timezones returns the distinct timezones of places, in order
+func Timezones(places []Place) []string {
+	var zones []string
+	for _, p := range places {
+		if !slices.Contains(zones, p.Timezone) {
+			zones = append(zones, p.Timezone)
+		}
+	}
+	return zones
+}
+
+// Timezone returns the timezone meant by places, as found by Lookup: the one
+// they all share, or else that of the city it is named after, such as London
+// for Europe/London, when none of the others names a timezone too. It fails
+// when places is empty or still ambiguous
+func Timezone(places []Place) (string, bool) {
+	zones := Timezones(places)
+	switch {
+	case len(zones) == 1:
+		return zones[0], true
+	case len(zones) == 0:
+		return "", false
+	}
+
+	named := 0
+	for _, p := range places {
+		named += p.namesTimezone()
+	}
+	if named == 1 && places[0].namesTimezone() == 1 {
+		return places[0].Timezone, true
+	}
+	return "", false
+}
+
+func parseCities(data string) []Place {
+	var found []Place
+	for _, fields := range tabRows(data) {
+		if len(fields) < 8 {
+			continue
+		}
+		p := Place{
+			Name:       fields[0],
+			Region:     fields[2],
+			RegionCode: fields[3],
+			Country:    fields[4],
+			Timezone:   fields[7],
+		}
+		if fields[1] != "" {
+			p.Aliases = strings.Split(fields[1], ",")
+		}
+		p.Latitude, _ = strconv.ParseFloat(fields[5], 64)
+		p.Longitude, _ = strconv.ParseFloat(fields[6], 64)
+		found = append(found, p)
+	}
+	return found
+}
+
+// parseZones reads zone.tab, naming each place after the last part of its
+// timezone, e.g. "Sao Paulo" for America/Sao_Paulo
+func parseZones(data string) []Place {
+	var found []Place
+	for _, fields := range tabRows(data) {
+		if len(fields) < 3 {
+			continue
+		}
+		name := fields[2][strings.LastIndex(fields[2], "/")+1:]
+		lat, lon := parseISO6709(fields[1])
+		found = append(found, Place{
+			Name:      strings.ReplaceAll(name, "_", " "),
+			Country:   fields[0],
+			Latitude:  lat,
+			Longitude: lon,
+			Timezone:  fields[2],
+		})
+	}
+	return found
+}
+
+// parseCountries maps country codes to the normalized names of the country. A
+// name with a note, such as "Korea (South)", is also known without it, and by
+// the note when it is an abbreviation, such as "UK" in "Britain (UK)"
+func parseCountries(data string) map[string][]string {
+	found := make(map[string][]string)
+	for _, fields := range tabRows(data) {
+		if len(fields) < 2 {
+			continue
+		}
+		name := fields[1]
+		names := []string{normalizeCity(name)}
+		if open := strings.Index(name, " ("); open >= 0 && strings.HasSuffix(name, ")") {
+			names = append(names, normalizeCity(name[:open]))
+			if note := name[open+2 : len(name)-1]; note == strings.ToUpper(note) {
+				names = append(names, normalizeCity(note))
+			}
+		}
+		found[fields[0]] = names
+	}
+	return found
+}
+
+// parseISO6709 parses coordinates such as +4230+00131 or +394441-1045903
+func parseISO6709(s string) (lat, lon float64) {
+	split := strings.LastIndexAny(s, "+-")
+	if split <= 0 {
+		return 0, 0
+	}
+	return parseDegrees(s[:split], 2), parseDegrees(s[split:], 3)
+}
+
+// parseDegrees parses a signed DDMM[SS] or DDDMM[SS] value
+func parseDegrees(s string, degreeDigits int) float64 {
+	if len(s) < 1+degreeDigits+2 {
+		return 0
+	}
+	sign := 1.0
+	if s[0] == '-' {
+		sign = -1
+	}
+	digits := s[1:]
+
+	deg, _ := strconv.Atoi(digits[:degreeDigits])
+	min, _ := strconv.Atoi(digits[degreeDigits : degreeDigits+2])
+	var sec int
+	if len(digits) >= degreeDigits+4 {
+		sec, _ = strconv.Atoi(digits[degreeDigits+2 : degreeDigits+4])
+	}
+	return sign * (float64(deg) + float64(min)/60 + float64(sec)/3600)
+}
+
+// tabRows splits a tab separated file into rows, skipping comments and blank
+// lines
+func tabRows(data string) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rows = append(rows, strings.Split(line, "\t"))
+	}
+	return rows
+}
+
+// normalizeCity folds case and accents, drops dots and treats '-', '_' and
+// runs of spaces as a single space, so that "St. Louis" matches "st louis"
+func normalizeCity(city string) string {
+	city = strings.NewReplacer("-", " ", "_", " ", ".", "").Replace(fold.Text(city))
+	return strings.Join(strings.Fields(city), " ")
+}
@@ -0,0 +1,125 @@
+package geo
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPlaces(t *testing.T) {
+	places := Places()
+	if len(places) < 700 {
+		t.Fatalf("got %d places, expected the gazetteer and zone.tab", len(places))
+	}
+
+	load()
+	for _, p := range places {
+		if p.Name == "" {
+			t.Errorf("place without a name: %+v", p)
+		}
+		if _, ok := countries[p.Country]; !ok {
+			t.Errorf("%s: unknown country code %q", p, p.Country)
+		}
+		if p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+			t.Errorf("%s: invalid coordinates %v, %v", p, p.Latitude, p.Longitude)
+		}
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			t.Errorf("%s: %v", p, err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "Pune", want: []string{"Asia/Kolkata"}},
+		{query: "bombay", want: []string{"Asia/Kolkata"}},
+		{query: "são paulo", want: []string{"America/Sao_Paulo"}},
+		{query: "Sao Paulo", want: []string{"America/Sao_Paulo"}},
+		{query: " New  York ", want: []string{"America/New_York"}},
+		{query: "st louis", want: []string{"America/Chicago"}},
+		{query: "buenos-aires", want: []string{"America/Argentina/Buenos_Aires"}},
+		{query: "Poggibonsi", want: []string{"Europe/Rome"}},
+		{query: "Portland", want: []string{"America/Los_Angeles", "America/New_York"}},
+		{query: "Portland, OR", want: []string{"America/Los_Angeles"}},
+		{query: "portland, maine", want: []string{"America/New_York"}},
+		{query: "Portland, US", want: []string{"America/Los_Angeles", "America/New_York"}},
+		{query: "London", want: []string{"Europe/London", "America/Toronto"}},
+		{query: "London, UK", want: []string{"Europe/London"}},
+		{query: "Valencia, Spain", want: []string{"Europe/Madrid"}},
+		{query: "Hyderabad, india", want: []string{"Asia/Kolkata"}},
+		{query: "Kinshasa", want: []string{"Africa/Kinshasa"}},
+		{query: "Portland, France", want: nil},
+		{query: "Atlantis", want: nil},
+		{query: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := Timezones(Lookup(tt.query))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	tests := []struct {
+		query  string
+		want   string
+		wantOK bool
+	}{
+		{query: "Pune", want: "Asia/Kolkata", wantOK: true},
+		{query: "London", want: "Europe/London", wantOK: true},
+		{query: "London, ON", want: "America/Toronto", wantOK: true},
+		{query: "Portland", wantOK: false},
+		{query: "Atlantis", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, ok := Timezone(Lookup(tt.query))
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("got %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLookup_ZoneTab(t *testing.T) {
+	// Ushuaia is only listed in zone.tab
+	places := Lookup("Ushuaia")
+	if len(places) != 1 {
+		t.Fatalf("got %v, want one place", places)
+	}
+
+	p := places[0]
+	if p.Timezone != "America/Argentina/Ushuaia" || p.Country != "AR" {
+		t.Errorf("got %+v", p)
+	}
+	if p.Latitude > -54 || p.Latitude < -55 || p.Longitude > -68 || p.Longitude < -69 {
+		t.Errorf("got coordinates %v, %v, want about -54.8, -68.3", p.Latitude, p.Longitude)
+	}
+}
+
+func TestPlace_String(t *testing.T) {
+	tests := []struct {
+		place Place
+		want  string
+	}{
+		{place: Place{Name: "Portland", Region: "Oregon", Country: "US"}, want: "Portland, Oregon, US"},
+		{place: Place{Name: "Mexico City", Region: "Mexico City", Country: "MX"}, want: "Mexico City, MX"},
+		{place: Place{Name: "Lisbon", Country: "PT"}, want: "Lisbon, PT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.place.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/yaml"
+)
+
+// Formatter writes rows to w in a machine-readable format
+type Formatter func(w io.Writer, rows []Row) error
+
+// Formatters maps output format names to their formatter
+var Formatters = map[string]Formatter{
+	"json": FormatJSON,
+	"csv":  FormatCSV,
+	"tsv":  FormatTSV,
+	"yaml": FormatYAML,
+}
+
+// FormatNames returns the names of the available formatters, sorted
+func FormatNames() []string {
+	names := make([]string, 0, len(Formatters))
+	for name := range Formatters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// record is the flat representation of a row shared by every format
+type record struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	City      string `json:"city"`
+	Timezone  string `json:"timezone"`
+	LocalTime string `json:"local_time"`
+	UTCOffset string `json:"utc_offset"`
+	Status    string `json:"status"`
+}
+
+var recordFields = []string{"id", "name", "city", "timezone", "local_time", "utc_offset", "status"}
+
+func toRecord(r Row) record {
+	rec := record{
+		ID:       r.ID,
+		Name:     r.Name,
+		City:     r.City,
+		Timezone: r.Timezone,
+		Status:   string(r.Status),
+	}
+
+	if r.Err != nil {
+		rec.Status = "invalid timezone"
+		return rec
+	}
+
+	rec.LocalTime = r.LocalTime.Format(time.RFC3339)
+	rec.UTCOffset = r.Offset
+	return rec
+}
+
+func (rec record) values() []string {
+	return []string{rec.ID, rec.Name, rec.City, rec.Timezone, rec.LocalTime, rec.UTCOffset, rec.Status}
+}
+
+// FormatJSON writes rows as an indented JSON array
+func FormatJSON(w io.Writer, rows []Row) error {
+	records := make([]record, len(rows))
+	for i, r := range rows {
+		records[i] = toRecord(r)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// FormatCSV writes rows as comma separated values with a header line
+func FormatCSV(w io.Writer, rows []Row) error {
+	return writeDelimited(w, rows, ',')
+}
+
+// FormatTSV writes rows as tab separated values with a header line
+func FormatTSV(w io.Writer, rows []Row) error {
+	return writeDelimited(w, rows, '\t')
+}
+
+func writeDelimited(w io.Writer, rows []Row, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	if err := cw.Write(recordFields); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		if err := cw.Write(toRecord(r).values()); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// FormatYAML writes rows as a YAML sequence of mappings
+func FormatYAML(w io.Writer, rows []Row) error {
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+
+	var sb strings.Builder
+	for _, r := range rows {
+		for i, value := range toRecord(r).values() {
+			prefix := "  "
+			if i == 0 {
+				prefix = "- "
+			}
+			fmt.Fprintf(&sb, "%s%s: %s\n", prefix, recordFields[i], yaml.Quote(value))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/schedule"
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+// Row is a colleague's local time and availability at a given instant. It is
+// the data passed to user-defined output templates, one row at a time
+type Row struct {
+	// ID is the colleague's permanent identifier
+	ID string
+	// Name, City and Timezone are copied from the colleague
+	Name     string
+	City     string
+	Timezone string
+	Tags     types.Tags
+	// LocalTime is the instant expressed in the colleague's timezone
+	LocalTime time.Time
+	// Offset is the UTC offset at LocalTime, formatted as +05:30
+	Offset string
+	// Status is the availability at LocalTime: work, extended, off or day off
+	Status schedule.Status
+	// UntilWorkStart is the time left until working hours start, zero while working
+	UntilWorkStart time.Duration
+	// UntilWorkEnd is the time left until working hours end, zero when not working
+	UntilWorkEnd time.Duration
+	// Err is set when the colleague's timezone could not be loaded, in which
+	// case the time related fields are empty
+	Err error
+}
+
+// BuildRows computes a row for every colleague at the instant now
+func BuildRows(colleagues []types.Colleague, now time.Time) []Row {
+	rows := make([]Row, 0, len(colleagues))
+	for _, c := range colleagues {
+		row := Row{
+			ID:       c.ID,
+			Name:     c.Name,
+			City:     c.City,
+			Timezone: c.Timezone,
+			Tags:     c.Tags,
+		}
+
+		local, status, err := schedule.At(now, c)
+		if err != nil {
+			row.Err = err
+			rows = append(rows, row)
+			continue
+		}
+
+		row.LocalTime = local
+		row.Offset = local.Format("-07:00")
+		row.Status = status
+		row.UntilWorkStart = schedule.UntilWorkStart(local, c)
+		row.UntilWorkEnd = schedule.UntilWorkEnd(local, c)
+		rows = append(rows, row)
+	}
+	return rows
+}
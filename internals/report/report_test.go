@@ -0,0 +1,164 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/schedule"
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+var testInstant = time.Date(2025, 11, 20, 9, 30, 0, 0, time.UTC)
+
+func testRows(t *testing.T) []Row {
+	t.Helper()
+	return BuildRows([]types.Colleague{
+		{ID: "a1b2c3", Name: "Alice", City: "London", Timezone: "Europe/London"},
+		{ID: "d4e5f6", Name: "Priya", City: "Pune", Timezone: "Asia/Kolkata"},
+		{ID: "0a0b0c", Name: "Bob \"the builder\", Jr", City: "NYC", Timezone: "America/New_York"},
+	}, testInstant)
+}
+
+func TestBuildRows(t *testing.T) {
+	rows := BuildRows([]types.Colleague{
+		{ID: "d4e5f6", Name: "Priya", City: "Pune", Timezone: "Asia/Kolkata"},
+		{ID: "0a0b0c", Name: "Bob", City: "NYC", Timezone: "Mars/Olympus"},
+	}, testInstant)
+
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	priya := rows[0]
+	if got := priya.LocalTime.Format("15:04"); got != "15:00" {
+		t.Errorf("got local time %s, want 15:00", got)
+	}
+
+	if priya.Offset != "+05:30" {
+		t.Errorf("got offset %q, want %q", priya.Offset, "+05:30")
+	}
+
+	if priya.Status != schedule.StatusWork {
+		t.Errorf("got status %q, want %q", priya.Status, schedule.StatusWork)
+	}
+
+	if rows[1].Err == nil {
+		t.Error("expected error for invalid timezone")
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatJSON(&buf, testRows(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("got %d records, want 3", len(got))
+	}
+
+	want := map[string]string{
+		"id":         "d4e5f6",
+		"name":       "Priya",
+		"city":       "Pune",
+		"timezone":   "Asia/Kolkata",
+		"local_time": "2025-11-20T15:00:00+05:30",
+		"utc_offset": "+05:30",
+		"status":     "work",
+	}
+	for key, value := range want {
+		if got[1][key] != value {
+			t.Errorf("got %s %q, want %q", key, got[1][key], value)
+		}
+	}
+}
+
+func TestFormatJSON_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatJSON(&buf, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("got %q, want empty array", buf.String())
+	}
+}
+
+func TestFormatDelimited(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter Formatter
+		comma     rune
+	}{
+		{name: "csv", formatter: FormatCSV, comma: ','},
+		{name: "tsv", formatter: FormatTSV, comma: '\t'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.formatter(&buf, testRows(t)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			r := csv.NewReader(&buf)
+			r.Comma = tt.comma
+			records, err := r.ReadAll()
+			if err != nil {
+				t.Fatalf("failed to parse output: %v", err)
+			}
+
+			if len(records) != 4 {
+				t.Fatalf("got %d lines, want header and 3 rows", len(records))
+			}
+
+			if strings.Join(records[0], ",") != "id,name,city,timezone,local_time,utc_offset,status" {
+				t.Errorf("unexpected header %v", records[0])
+			}
+
+			if records[3][1] != "Bob \"the builder\", Jr" {
+				t.Errorf("got name %q, want it preserved", records[3][1])
+			}
+
+			if records[2][4] != "2025-11-20T15:00:00+05:30" {
+				t.Errorf("got local time %q", records[2][4])
+			}
+		})
+	}
+}
+
+func TestFormatYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatYAML(&buf, testRows(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"- id: \"a1b2c3\"\n  name: \"Alice\"\n",
+		"  local_time: \"2025-11-20T15:00:00+05:30\"\n",
+		"  name: \"Bob \\\"the builder\\\", Jr\"\n",
+		"  status: \"work\"\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := FormatYAML(&buf, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("got %q, want empty sequence", buf.String())
+	}
+}
@@ -0,0 +1,195 @@
+package report
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/matteo-gildone/teamtime/internals/schedule"
+)
+
+var (
+	ErrInvalidSort  = errors.New("invalid sort key")
+	ErrInvalidGroup = errors.New("invalid group key")
+)
+
+// Sort keys accepted by SortRows
+const (
+	SortName      = "name"
+	SortCity      = "city"
+	SortOffset    = "offset"
+	SortLocalTime = "localtime"
+	SortStatus    = "status"
+)
+
+// Group keys accepted by GroupRows
+const (
+	GroupTimezone = "timezone"
+	GroupCity     = "city"
+	GroupStatus   = "status"
+	GroupTag      = "tag"
+)
+
+// SortKeys and GroupKeys list the accepted keys in the order they are documented
+var (
+	SortKeys  = []string{SortName, SortCity, SortOffset, SortLocalTime, SortStatus}
+	GroupKeys = []string{GroupTimezone, GroupCity, GroupStatus, GroupTag}
+)
+
+// statusOrder ranks statuses from most to least available
+var statusOrder = []schedule.Status{schedule.StatusWork, schedule.StatusExtended, schedule.StatusOff, schedule.StatusDayOff}
+
+// SortRows sorts rows in place by key. The sort is stable, so rows that
+// compare equal keep their order, and rows with an invalid timezone go last
+func SortRows(rows []Row, key string) error {
+	var compare func(a, b Row) int
+	switch key {
+	case SortName:
+		compare = func(a, b Row) int { return compareText(a.Name, b.Name) }
+	case SortCity:
+		compare = func(a, b Row) int { return compareText(a.City, b.City) }
+	case SortOffset:
+		compare = func(a, b Row) int { return cmp.Compare(offsetSeconds(a), offsetSeconds(b)) }
+	case SortLocalTime:
+		compare = func(a, b Row) int { return cmp.Compare(minuteOfDay(a), minuteOfDay(b)) }
+	case SortStatus:
+		compare = func(a, b Row) int { return cmp.Compare(statusRank(a.Status), statusRank(b.Status)) }
+	default:
+		return fmt.Errorf("%w %q, use %s", ErrInvalidSort, key, strings.Join(SortKeys, ", "))
+	}
+
+	slices.SortStableFunc(rows, func(a, b Row) int {
+		if (a.Err != nil) != (b.Err != nil) {
+			if a.Err != nil {
+				return 1
+			}
+			return -1
+		}
+		if a.Err != nil {
+			return 0
+		}
+		return compare(a, b)
+	})
+	return nil
+}
+
+// Group is a titled subset of rows
+type Group struct {
+	Title string
+	Rows  []Row
+	key   string
+}
+
+// GroupRows splits rows into groups by key, keeping the order of rows within
+// each group. Timezone groups share a UTC offset, and so a local time, and are
+// ordered by offset; city and tag groups are alphabetical and status groups go
+// from most to least available. A row appears under each of its tags, and
+// rows with an invalid timezone are grouped last
+func GroupRows(rows []Row, key string) ([]Group, error) {
+	var keys func(r Row) []string
+	var title func(r Row, key string) string
+	var compare func(a, b Group) int
+
+	switch key {
+	case GroupTimezone:
+		keys = func(r Row) []string { return []string{r.Offset} }
+		title = func(r Row, _ string) string {
+			return fmt.Sprintf("UTC%s · %s", r.Offset, r.LocalTime.Format("15:04 (Mon 02 Jan)"))
+		}
+		compare = func(a, b Group) int { return cmp.Compare(offsetSeconds(a.Rows[0]), offsetSeconds(b.Rows[0])) }
+	case GroupCity:
+		keys = func(r Row) []string { return []string{strings.ToLower(strings.TrimSpace(r.City))} }
+		title = func(r Row, _ string) string { return r.City }
+		compare = func(a, b Group) int { return compareText(a.Title, b.Title) }
+	case GroupStatus:
+		keys = func(r Row) []string { return []string{string(r.Status)} }
+		title = func(r Row, _ string) string { return statusTitle(r.Status) }
+		compare = func(a, b Group) int {
+			return cmp.Compare(statusRank(a.Rows[0].Status), statusRank(b.Rows[0].Status))
+		}
+	case GroupTag:
+		keys = func(r Row) []string {
+			if len(r.Tags) == 0 {
+				return []string{""}
+			}
+			return r.Tags
+		}
+		title = func(_ Row, key string) string {
+			if key == "" {
+				return "untagged"
+			}
+			return key
+		}
+		compare = func(a, b Group) int {
+			if (a.key == "") != (b.key == "") {
+				if a.key == "" {
+					return 1
+				}
+				return -1
+			}
+			return cmp.Compare(a.key, b.key)
+		}
+	default:
+		return nil, fmt.Errorf("%w %q, use %s", ErrInvalidGroup, key, strings.Join(GroupKeys, ", "))
+	}
+
+	var groups []Group
+	var invalid []Row
+	index := make(map[string]int)
+	for _, r := range rows {
+		if r.Err != nil {
+			invalid = append(invalid, r)
+			continue
+		}
+		for _, k := range keys(r) {
+			i, ok := index[k]
+			if !ok {
+				i = len(groups)
+				index[k] = i
+				groups = append(groups, Group{Title: title(r, k), key: k})
+			}
+			groups[i].Rows = append(groups[i].Rows, r)
+		}
+	}
+
+	slices.SortStableFunc(groups, compare)
+	if len(invalid) > 0 {
+		groups = append(groups, Group{Title: "Invalid timezone", Rows: invalid})
+	}
+	return groups, nil
+}
+
+func compareText(a, b string) int {
+	return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func offsetSeconds(r Row) int {
+	_, offset := r.LocalTime.Zone()
+	return offset
+}
+
+func minuteOfDay(r Row) int {
+	return r.LocalTime.Hour()*60 + r.LocalTime.Minute()
+}
+
+func statusRank(s schedule.Status) int {
+	if i := slices.Index(statusOrder, s); i >= 0 {
+		return i
+	}
+	return len(statusOrder)
+}
+
+func statusTitle(s schedule.Status) string {
+	switch s {
+	case schedule.StatusWork:
+		return "Work"
+	case schedule.StatusExtended:
+		return "Extended"
+	case schedule.StatusOff:
+		return "Off"
+	default:
+		return "Day off"
+	}
+}
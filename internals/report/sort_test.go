@@ -0,0 +1,137 @@
+package report
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func rowNames(rows []Row) []string {
+	names := make([]string, len(rows))
+	for i, r := range rows {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func TestSortRows(t *testing.T) {
+	// 09:30 in London (work), 15:00 in Pune (work), 04:30 in New York (off)
+	// and 18:30 in Tokyo (extended) at the test instant
+	colleagues := []types.Colleague{
+		{Name: "priya", City: "Pune", Timezone: "Asia/Kolkata"},
+		{Name: "Bob", City: "NYC", Timezone: "America/New_York"},
+		{Name: "Mars", City: "Olympus", Timezone: "Mars/Olympus"},
+		{Name: "Kenji", City: "Tokyo", Timezone: "Asia/Tokyo"},
+		{Name: "Alice", City: "London", Timezone: "Europe/London"},
+	}
+
+	tests := []struct {
+		key     string
+		want    []string
+		wantErr error
+	}{
+		{key: SortName, want: []string{"Alice", "Bob", "Kenji", "priya", "Mars"}},
+		{key: SortCity, want: []string{"Alice", "Bob", "priya", "Kenji", "Mars"}},
+		{key: SortOffset, want: []string{"Bob", "Alice", "priya", "Kenji", "Mars"}},
+		{key: SortLocalTime, want: []string{"Bob", "Alice", "priya", "Kenji", "Mars"}},
+		{key: SortStatus, want: []string{"priya", "Alice", "Kenji", "Bob", "Mars"}},
+		{key: "age", wantErr: ErrInvalidSort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			rows := BuildRows(colleagues, testInstant)
+			err := SortRows(rows, tt.key)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := rowNames(rows); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupRows(t *testing.T) {
+	colleagues := []types.Colleague{
+		{Name: "Priya", City: "Pune", Timezone: "Asia/Kolkata", Tags: types.Tags{"backend"}},
+		{Name: "Ana", City: "Lisbon", Timezone: "Europe/Lisbon"},
+		{Name: "Bob", City: "NYC", Timezone: "America/New_York", Tags: types.Tags{"backend", "oncall"}},
+		{Name: "Alice", City: "london", Timezone: "Europe/London", Tags: types.Tags{"oncall"}},
+		{Name: "Ben", City: "London", Timezone: "Europe/London"},
+		{Name: "Mars", City: "Olympus", Timezone: "Mars/Olympus"},
+	}
+
+	type group struct {
+		title string
+		names []string
+	}
+
+	tests := []struct {
+		key     string
+		want    []group
+		wantErr error
+	}{
+		{key: GroupTimezone, want: []group{
+			{"UTC-05:00 · 04:30 (Thu 20 Nov)", []string{"Bob"}},
+			{"UTC+00:00 · 09:30 (Thu 20 Nov)", []string{"Ana", "Alice", "Ben"}},
+			{"UTC+05:30 · 15:00 (Thu 20 Nov)", []string{"Priya"}},
+			{"Invalid timezone", []string{"Mars"}},
+		}},
+		{key: GroupCity, want: []group{
+			{"Lisbon", []string{"Ana"}},
+			{"london", []string{"Alice", "Ben"}},
+			{"NYC", []string{"Bob"}},
+			{"Pune", []string{"Priya"}},
+			{"Invalid timezone", []string{"Mars"}},
+		}},
+		{key: GroupStatus, want: []group{
+			{"Work", []string{"Priya", "Ana", "Alice", "Ben"}},
+			{"Off", []string{"Bob"}},
+			{"Invalid timezone", []string{"Mars"}},
+		}},
+		{key: GroupTag, want: []group{
+			{"backend", []string{"Priya", "Bob"}},
+			{"oncall", []string{"Bob", "Alice"}},
+			{"untagged", []string{"Ana", "Ben"}},
+			{"Invalid timezone", []string{"Mars"}},
+		}},
+		{key: "team", wantErr: ErrInvalidGroup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			groups, err := GroupRows(BuildRows(colleagues, testInstant), tt.key)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(groups) != len(tt.want) {
+				t.Fatalf("got %d groups, want %d", len(groups), len(tt.want))
+			}
+			for i, g := range groups {
+				if g.Title != tt.want[i].title {
+					t.Errorf("group %d: got title %q, want %q", i, g.Title, tt.want[i].title)
+				}
+				if got := rowNames(g.Rows); !slices.Equal(got, tt.want[i].names) {
+					t.Errorf("group %q: got %v, want %v", g.Title, got, tt.want[i].names)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/schedule"
+	"github.com/matteo-gildone/teamtime/internals/styles"
+)
+
+// NewTemplate parses a text/template executed once per Row. Besides the
+// built-in functions it provides:
+//
+//	pad N s          left-align s in N columns
+//	padLeft N s      right-align s in N columns
+//	color NAME s     render s with a style: red, green, yellow, cyan, bold, dim
+//	statusColor S s  render s with the colour used for status S in the table
+//	relative D       a duration as a short relative time, e.g. "2h15m" or "now"
+//	upper s, lower s change case
+//
+// Colours follow style, so they are dropped when colour output is disabled
+func NewTemplate(text string, style styles.Style) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"pad": func(n int, v any) string {
+			return fmt.Sprintf("%-*s", n, fmt.Sprint(v))
+		},
+		"padLeft": func(n int, v any) string {
+			return fmt.Sprintf("%*s", n, fmt.Sprint(v))
+		},
+		"color": func(name string, v any) (string, error) {
+			s, err := namedStyle(style, name)
+			if err != nil {
+				return "", err
+			}
+			return s.Render(fmt.Sprint(v)), nil
+		},
+		"statusColor": func(status schedule.Status, v any) string {
+			return StatusStyle(style, status).Render(fmt.Sprint(v))
+		},
+		"relative": FormatDuration,
+		"upper":    func(v any) string { return strings.ToUpper(fmt.Sprint(v)) },
+		"lower":    func(v any) string { return strings.ToLower(fmt.Sprint(v)) },
+	}
+
+	tmpl, err := template.New("row").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// FormatTemplate executes tmpl for every row, one line per row
+func FormatTemplate(w io.Writer, tmpl *template.Template, rows []Row) error {
+	for _, r := range rows {
+		if err := tmpl.Execute(w, r); err != nil {
+			return fmt.Errorf("failed to render %s: %w", r.Name, err)
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StatusStyle returns the style used to render a status
+func StatusStyle(style styles.Style, status schedule.Status) styles.Style {
+	switch status {
+	case schedule.StatusWork:
+		return style.Bold().Cyan()
+	case schedule.StatusExtended:
+		return style.Bold().Yellow()
+	case schedule.StatusOff:
+		return style.Bold().Red()
+	case schedule.StatusDayOff:
+		return style.Dim()
+	default:
+		return style
+	}
+}
+
+func namedStyle(style styles.Style, name string) (styles.Style, error) {
+	switch strings.ToLower(name) {
+	case "red":
+		return style.Red(), nil
+	case "green":
+		return style.Green(), nil
+	case "yellow":
+		return style.Yellow(), nil
+	case "cyan":
+		return style.Cyan(), nil
+	case "bold":
+		return style.Bold(), nil
+	case "dim":
+		return style.Dim(), nil
+	default:
+		return style, fmt.Errorf("unknown color %q", name)
+	}
+}
+
+// FormatDuration formats a duration rounded to the minute, e.g. "45m", "2h15m"
+// or "now" for durations under a minute
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < 0 {
+		d = -d
+	}
+	if d < time.Minute {
+		return "now"
+	}
+
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh%dm", h, m)
+	}
+}
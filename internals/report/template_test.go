@@ -0,0 +1,111 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/styles"
+)
+
+func TestFormatTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		noColor bool
+		want    string
+	}{
+		{
+			name:    "fields",
+			text:    `{{.Name}} {{.LocalTime.Format "15:04"}} {{.Status}}`,
+			noColor: true,
+			want:    "Alice 09:30 work\nPriya 15:00 work\nBob \"the builder\", Jr 04:30 off\n",
+		},
+		{
+			name:    "padding and offset",
+			text:    `{{pad 6 .Name}}|{{padLeft 7 .Offset}}`,
+			noColor: true,
+			want:    "Alice | +00:00\nPriya | +05:30\nBob \"the builder\", Jr| -05:00\n",
+		},
+		{
+			name:    "relative times",
+			text:    `{{.Name}}: {{if eq .Status "work"}}ends in {{relative .UntilWorkEnd}}{{else}}starts in {{relative .UntilWorkStart}}{{end}}`,
+			noColor: true,
+			want:    "Alice: ends in 7h30m\nPriya: ends in 2h\nBob \"the builder\", Jr: starts in 4h30m\n",
+		},
+		{
+			name:    "colors",
+			text:    `{{color "green" .ID}} {{.Status | upper | statusColor .Status}}`,
+			noColor: false,
+			want:    "\033[32ma1b2c3\033[0m \033[1;36mWORK\033[0m\n\033[32md4e5f6\033[0m \033[1;36mWORK\033[0m\n\033[32m0a0b0c\033[0m \033[1;31mOFF\033[0m\n",
+		},
+		{
+			name:    "colors disabled",
+			text:    `{{color "red" .ID}}`,
+			noColor: true,
+			want:    "a1b2c3\nd4e5f6\n0a0b0c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := NewTemplate(tt.text, styles.NewStylesWithNoColor(tt.noColor))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := FormatTemplate(&buf, tmpl, testRows(t)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTemplate_Errors(t *testing.T) {
+	if _, err := NewTemplate(`{{.Name`, styles.NewStylesWithNoColor(true)); err == nil {
+		t.Error("expected parse error")
+	}
+
+	tmpl, err := NewTemplate(`{{color "purple" .Name}}`, styles.NewStylesWithNoColor(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTemplate(&buf, tmpl, testRows(t)); err == nil {
+		t.Error("expected error for unknown color")
+	}
+
+	tmpl, err = NewTemplate(`{{.Nickname}}`, styles.NewStylesWithNoColor(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := FormatTemplate(&buf, tmpl, testRows(t)); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{in: 20 * time.Second, want: "now"},
+		{in: 45 * time.Minute, want: "45m"},
+		{in: 2 * time.Hour, want: "2h"},
+		{in: 63*time.Hour + 15*time.Minute, want: "63h15m"},
+		{in: -90 * time.Minute, want: "1h30m"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.in); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
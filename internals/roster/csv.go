@@ -0,0 +1,51 @@
+package roster
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// readCSV reads comma separated values whose first line names the fields. A
+// byte order mark, as written by spreadsheets, is ignored
+func readCSV(data []byte) ([]record, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSyntax, err)
+	}
+
+	fields := make([]string, len(header))
+	for i, name := range header {
+		if fields[i], err = canonicalField(name); err != nil {
+			return nil, &LineError{Line: 1, Err: err}
+		}
+	}
+
+	var records []record
+	for {
+		values, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSyntax, err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		rec := record{line: line, fields: make(map[string]string, len(fields))}
+		for i, value := range values {
+			rec.fields[fields[i]] = value
+		}
+		records = append(records, rec)
+	}
+}
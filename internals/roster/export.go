@@ -0,0 +1,155 @@
+package roster
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+	"github.com/matteo-gildone/teamtime/internals/yaml"
+)
+
+// writers maps format names to their writer. now is the instant at which
+// formats showing UTC offsets compute them
+var writers = map[string]func(w io.Writer, colleagues []types.Colleague, now time.Time) error{
+	FormatCSV:      writeCSV,
+	FormatJSON:     writeJSON,
+	FormatYAML:     writeYAML,
+	FormatMarkdown: writeMarkdown,
+	FormatICS:      writeICS,
+}
+
+// ExportFormats returns the names of the writable formats, sorted
+func ExportFormats() []string {
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Write writes colleagues to w in format. CSV, JSON and YAML keep every field,
+// so that reading the result gives the same colleagues back
+func Write(w io.Writer, colleagues []types.Colleague, format string, now time.Time) error {
+	write, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("%w %q, use %s", ErrUnknownFormat, format, strings.Join(ExportFormats(), ", "))
+	}
+	return write(w, colleagues, now)
+}
+
+// values returns the fields of c in the order of Fields, empty when unset
+func values(c types.Colleague) []string {
+	var workHours, extendedHours string
+	if c.WorkHours != nil {
+		workHours = c.WorkHours.String()
+	}
+	if c.ExtendedHours != nil {
+		extendedHours = c.ExtendedHours.String()
+	}
+	return []string{c.ID, c.Name, c.City, c.Timezone, workHours, extendedHours, c.WorkDays.String(), c.Tags.String()}
+}
+
+func writeCSV(w io.Writer, colleagues []types.Colleague, _ time.Time) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Fields); err != nil {
+		return err
+	}
+
+	for _, c := range colleagues {
+		if err := cw.Write(values(c)); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON writes the colleagues as they are stored in the colleagues file
+func writeJSON(w io.Writer, colleagues []types.Colleague, _ time.Time) error {
+	if colleagues == nil {
+		colleagues = []types.Colleague{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(colleagues)
+}
+
+func writeYAML(w io.Writer, colleagues []types.Colleague, _ time.Time) error {
+	if len(colleagues) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+
+	var sb strings.Builder
+	for _, c := range colleagues {
+		prefix := "- "
+		for i, value := range values(c) {
+			if value == "" {
+				continue
+			}
+
+			if Fields[i] == FieldTags {
+				quoted := make([]string, len(c.Tags))
+				for j, tag := range c.Tags {
+					quoted[j] = yaml.Quote(tag)
+				}
+				value = "[" + strings.Join(quoted, ", ") + "]"
+			} else {
+				value = yaml.Quote(value)
+			}
+
+			fmt.Fprintf(&sb, "%s%s: %s\n", prefix, Fields[i], value)
+			prefix = "  "
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeMarkdown writes a table with the effective schedule of each colleague
+// and the UTC offset of their timezone at now
+func writeMarkdown(w io.Writer, colleagues []types.Colleague, now time.Time) error {
+	var sb strings.Builder
+	sb.WriteString("| Name | City | Timezone | UTC offset | Work hours | Work days | Tags |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+
+	for _, c := range colleagues {
+		offset := "?"
+		if loc, err := time.LoadLocation(c.Timezone); err == nil {
+			offset = "UTC" + now.In(loc).Format("-07:00")
+		}
+
+		cells := []string{
+			c.Name,
+			c.City,
+			c.Timezone,
+			offset,
+			c.EffectiveWorkHours().String(),
+			c.EffectiveWorkDays().String(),
+			strings.Join(c.Tags, ", "),
+		}
+		for i, cell := range cells {
+			cells[i] = markdownCell(cell)
+		}
+		fmt.Fprintf(&sb, "| %s |\n", strings.Join(cells, " | "))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// markdownCell escapes the characters that would break a table cell
+func markdownCell(s string) string {
+	s = strings.NewReplacer("\\", "\\\\", "|", "\\|", "\n", " ").Replace(s)
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,130 @@
+package roster
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func testColleagues(t *testing.T) []types.Colleague {
+	t.Helper()
+
+	var colleagues []types.Colleague
+	for _, tt := range []struct {
+		name, city, tz string
+		opts           []types.Option
+	}{
+		{name: "Priya", city: "Pune", tz: "Asia/Kolkata", opts: []types.Option{
+			types.WithWorkHours(types.Hours{Start: 8, End: 16}),
+			types.WithExtendedHours(types.Hours{Start: 6, End: 22}),
+			types.WithWorkDays(types.Weekdays{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday}),
+			types.WithTags(types.Tags{"oncall", "backend"}),
+		}},
+		{name: "Lúcio Bianchi", city: "Poggibonsi", tz: "Europe/Rome"},
+		{name: `Bob "the builder", Jr # 2`, city: "New York | NY", tz: "America/New_York", opts: []types.Option{
+			types.WithTags(types.Tags{"team.ny"}),
+		}},
+		{name: "O'Brien", city: "Dún Laoghaire", tz: "Europe/Dublin"},
+	} {
+		c, err := types.NewColleague(tt.name, tt.city, tt.tz, tt.opts...)
+		if err != nil {
+			t.Fatalf("failed to create colleague: %v", err)
+		}
+		colleagues = append(colleagues, c)
+	}
+	return colleagues
+}
+
+func TestWrite_RoundTrip(t *testing.T) {
+	now := time.Date(2025, 11, 20, 9, 30, 0, 0, time.UTC)
+
+	for _, format := range Formats() {
+		if _, ok := writers[format]; !ok {
+			continue
+		}
+		t.Run(format, func(t *testing.T) {
+			want := testColleagues(t)
+
+			var buf bytes.Buffer
+			if err := Write(&buf, want, format, now); err != nil {
+				t.Fatalf("failed to write: %v", err)
+			}
+
+			entries, err := Read(&buf, format)
+			if err != nil {
+				t.Fatalf("failed to read back: %v\n%s", err, buf.String())
+			}
+
+			got := make([]types.Colleague, len(entries))
+			for i, e := range entries {
+				got[i] = e.Colleague
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip changed the colleagues\ngot:  %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestWrite_Empty(t *testing.T) {
+	for _, format := range Formats() {
+		if _, ok := writers[format]; !ok {
+			continue
+		}
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, nil, format, time.Now()); err != nil {
+				t.Fatalf("failed to write: %v", err)
+			}
+
+			entries, err := Read(&buf, format)
+			if err != nil {
+				t.Fatalf("failed to read back: %v", err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("got %d entries, want none", len(entries))
+			}
+		})
+	}
+}
+
+func TestWrite_Markdown(t *testing.T) {
+	// 20 Nov 2025 is outside daylight saving time in Rome and New York
+	now := time.Date(2025, 11, 20, 9, 30, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, testColleagues(t), FormatMarkdown, now); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{
+		"| Name | City | Timezone | UTC offset | Work hours | Work days | Tags |",
+		"| --- | --- | --- | --- | --- | --- | --- |",
+		"| Priya | Pune | Asia/Kolkata | UTC+05:30 | 8-16 | sun,mon,tue,wed,thu | backend, oncall |",
+		"| Lúcio Bianchi | Poggibonsi | Europe/Rome | UTC+01:00 | 9-17 | mon,tue,wed,thu,fri |  |",
+		`| Bob "the builder", Jr # 2 | New York \| NY | America/New_York | UTC-05:00 | 9-17 | mon,tue,wed,thu,fri | team.ny |`,
+		"| O'Brien | Dún Laoghaire | Europe/Dublin | UTC+00:00 | 9-17 | mon,tue,wed,thu,fri |  |",
+	}
+
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d:\ngot:  %s\nwant: %s", i+1, lines[i], want[i])
+		}
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	err := Write(&bytes.Buffer{}, nil, "xml", time.Now())
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("expected %v, got %v", ErrUnknownFormat, err)
+	}
+}
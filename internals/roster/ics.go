@@ -0,0 +1,332 @@
+package roster
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+const (
+	// icsLocalTime is the layout of a local DATE-TIME value
+	icsLocalTime = "20060102T150405"
+	// icsLineLength is the longest content line in octets before folding
+	icsLineLength = 75
+	// icsRuleYears is how many years of transitions a VTIMEZONE is derived
+	// from, starting the year before now
+	icsRuleYears = 6
+	// icsSettledYears is how long a zone must go without transitions after
+	// those listed in its VTIMEZONE to be taken as settled on its offset
+	icsSettledYears = 20
+)
+
+var icsWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// writeICS writes an iCalendar (RFC 5545) calendar with a weekly event per
+// colleague over their working hours on their working days, in their own
+// timezone, starting on their first working day from now. Events are
+// transparent, so that subscribing does not mark the subscriber as busy
+func writeICS(w io.Writer, colleagues []types.Colleague, now time.Time) error {
+	var cal icsLines
+	cal.add("BEGIN", "VCALENDAR")
+	cal.add("VERSION", "2.0")
+	cal.add("PRODID", "-//teamtime//teamtime//EN")
+	cal.add("CALSCALE", "GREGORIAN")
+	cal.add("METHOD", "PUBLISH")
+	cal.add("X-WR-CALNAME", "teamtime")
+
+	locations := make(map[string]*time.Location)
+	var zones []string
+	for _, c := range colleagues {
+		if _, ok := locations[c.Timezone]; ok {
+			continue
+		}
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+		locations[c.Timezone] = loc
+		zones = append(zones, c.Timezone)
+	}
+
+	until := make(map[string]time.Time)
+	for _, tz := range zones {
+		until[tz] = writeVTimezone(&cal, tz, locations[tz], now)
+	}
+
+	for _, c := range colleagues {
+		writeVEvent(&cal, c, locations[c.Timezone], now, until[c.Timezone])
+	}
+
+	cal.add("END", "VCALENDAR")
+	_, err := io.WriteString(w, cal.String())
+	return err
+}
+
+// writeVEvent writes the weekly event covering the working hours of c. The
+// event stops repeating at until, unless it is zero
+func writeVEvent(cal *icsLines, c types.Colleague, loc *time.Location, now, until time.Time) {
+	days := c.EffectiveWorkDays()
+	hours := c.EffectiveWorkHours()
+
+	// the first working day from now, as a date free of DST gaps
+	local := now.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	for !days.Contains(day.Weekday()) {
+		day = day.AddDate(0, 0, 1)
+	}
+	start := day.Add(time.Duration(hours.Start) * time.Hour)
+	end := day.Add(time.Duration(hours.End) * time.Hour)
+
+	byDay := make([]string, len(days))
+	for i, d := range days {
+		byDay[i] = icsWeekdays[d]
+	}
+
+	cal.add("BEGIN", "VEVENT")
+	cal.add("UID", c.ID+"@teamtime")
+	cal.add("DTSTAMP", now.UTC().Format(icsLocalTime)+"Z")
+	cal.add("DTSTART;TZID="+c.Timezone, start.Format(icsLocalTime))
+	cal.add("DTEND;TZID="+c.Timezone, end.Format(icsLocalTime))
+	rrule := "FREQ=WEEKLY;BYDAY=" + strings.Join(byDay, ",")
+	if !until.IsZero() {
+		// with a TZID on DTSTART, UNTIL must be in UTC
+		rrule += ";UNTIL=" + until.UTC().Format(icsLocalTime) + "Z"
+	}
+	cal.add("RRULE", rrule)
+	cal.add("SUMMARY", icsText(c.Name+" working hours"))
+	cal.add("LOCATION", icsText(c.City))
+	if len(c.Tags) > 0 {
+		tags := make([]string, len(c.Tags))
+		for i, tag := range c.Tags {
+			tags[i] = icsText(tag)
+		}
+		cal.add("CATEGORIES", strings.Join(tags, ","))
+	}
+	cal.add("TRANSP", "TRANSPARENT")
+	cal.add("END", "VEVENT")
+}
+
+// transition is a change of UTC offset or of daylight saving time
+type transition struct {
+	at       time.Time
+	from, to int
+	name     string
+	dst      bool
+}
+
+// onset returns the local time of the transition before it happens, which is
+// how VTIMEZONE observances start
+func (t transition) onset() time.Time {
+	return t.at.In(time.FixedZone("", t.from))
+}
+
+// zoneTransitions lists the transitions of loc in [start, end)
+func zoneTransitions(loc *time.Location, start, end time.Time) []transition {
+	var found []transition
+	t := start.In(loc)
+	for {
+		_, next := t.ZoneBounds()
+		if next.IsZero() || !next.Before(end) {
+			return found
+		}
+
+		next = next.In(loc)
+		_, from := t.Zone()
+		name, to := next.Zone()
+		if from != to || t.IsDST() != next.IsDST() {
+			found = append(found, transition{at: next, from: from, to: to, name: name, dst: next.IsDST()})
+		}
+		t = next
+	}
+}
+
+// writeVTimezone writes the VTIMEZONE of loc. Observances that recur on the
+// same weekday of the same month every year, such as the last Sunday of
+// March, are written as yearly rules; otherwise every transition of the
+// icsRuleYears years from the year before now is listed as a plain
+// observance, from the last one before now. Unless the zone then keeps its offset for good, as in a zone
+// that abolished daylight saving time, the end of that window is returned,
+// after which the VTIMEZONE is no longer accurate. It returns the zero time
+// when the VTIMEZONE holds for good
+func writeVTimezone(cal *icsLines, tz string, loc *time.Location, now time.Time) time.Time {
+	first := now.UTC().Year() - 1
+	start := time.Date(first, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(icsRuleYears, 0, 0)
+	transitions := zoneTransitions(loc, start, end)
+
+	cal.add("BEGIN", "VTIMEZONE")
+	cal.add("TZID", tz)
+
+	if len(transitions) == 0 {
+		name, offset := start.In(loc).Zone()
+		writeObservance(cal, transition{at: start, from: offset, to: offset, name: name, dst: start.In(loc).IsDST()}, "19700101T000000", "")
+		cal.add("END", "VTIMEZONE")
+		return time.Time{}
+	}
+
+	var until time.Time
+	if rules, ok := yearlyRules(transitions, first); ok {
+		for _, r := range rules {
+			writeObservance(cal, r.transition, r.onset().Format(icsLocalTime), r.rrule)
+		}
+	} else {
+		// The events start today, so earlier transitions only matter for
+		// the offset in force, which the last of them gives
+		from := 0
+		for i, t := range transitions {
+			if t.at.Before(now.AddDate(0, 0, -1)) {
+				from = i
+			}
+		}
+		for _, t := range transitions[from:] {
+			writeObservance(cal, t, t.onset().Format(icsLocalTime), "")
+		}
+		if len(zoneTransitions(loc, end, end.AddDate(icsSettledYears, 0, 0))) > 0 {
+			until = end
+		}
+	}
+	cal.add("END", "VTIMEZONE")
+	return until
+}
+
+func writeObservance(cal *icsLines, t transition, dtstart, rrule string) {
+	kind := "STANDARD"
+	if t.dst {
+		kind = "DAYLIGHT"
+	}
+
+	cal.add("BEGIN", kind)
+	cal.add("DTSTART", dtstart)
+	if rrule != "" {
+		cal.add("RRULE", rrule)
+	}
+	cal.add("TZOFFSETFROM", icsOffset(t.from))
+	cal.add("TZOFFSETTO", icsOffset(t.to))
+	if t.name != "" {
+		cal.add("TZNAME", icsText(t.name))
+	}
+	cal.add("END", kind)
+}
+
+// yearlyRule is an observance repeating every year from its first transition
+type yearlyRule struct {
+	transition
+	rrule string
+}
+
+// yearlyRules turns transitions, covering icsRuleYears years from first, into
+// one yearly rule per kind of transition. It fails when a kind does not happen
+// once every year, on the same weekday of the same month at the same time
+func yearlyRules(transitions []transition, first int) ([]yearlyRule, bool) {
+	type ruleKey struct {
+		from, to int
+		name     string
+		dst      bool
+		month    time.Month
+		weekday  time.Weekday
+		clock    string
+	}
+
+	var keys []ruleKey
+	groups := make(map[ruleKey][]transition)
+	for _, t := range transitions {
+		onset := t.onset()
+		key := ruleKey{t.from, t.to, t.name, t.dst, onset.Month(), onset.Weekday(), onset.Format("150405")}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], t)
+	}
+
+	rules := make([]yearlyRule, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) != icsRuleYears {
+			return nil, false
+		}
+
+		week := weekOfMonth(group[0].onset())
+		nth, last := true, true
+		for year, t := range group {
+			onset := t.onset()
+			if onset.Year() != first+year {
+				return nil, false
+			}
+			nth = nth && weekOfMonth(onset) == week
+			last = last && onset.AddDate(0, 0, 7).Month() != onset.Month()
+		}
+
+		var byDay string
+		switch {
+		case last:
+			byDay = "-1" + icsWeekdays[key.weekday]
+		case nth:
+			byDay = fmt.Sprint(week) + icsWeekdays[key.weekday]
+		default:
+			return nil, false
+		}
+
+		rules = append(rules, yearlyRule{
+			transition: group[0],
+			rrule:      fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%s", key.month, byDay),
+		})
+	}
+	return rules, true
+}
+
+// weekOfMonth returns 1 for the first seven days of the month, 2 for the next
+// seven and so on
+func weekOfMonth(t time.Time) int {
+	return (t.Day()-1)/7 + 1
+}
+
+// icsOffset formats a UTC offset in seconds as +hhmm, or +hhmmss
+func icsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	offset := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if s := seconds % 60; s != 0 {
+		offset += fmt.Sprintf("%02d", s)
+	}
+	return offset
+}
+
+// icsText escapes a TEXT value
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// icsLines builds content lines ending in CRLF and folded at icsLineLength
+// octets, without splitting UTF-8 sequences
+type icsLines struct {
+	sb strings.Builder
+}
+
+func (l *icsLines) add(name, value string) {
+	line := name + ":" + value
+	limit := icsLineLength
+	for len(line) > limit {
+		cut := limit
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		l.sb.WriteString(line[:cut])
+		l.sb.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space
+		limit = icsLineLength - 1
+	}
+	l.sb.WriteString(line)
+	l.sb.WriteString("\r\n")
+}
+
+func (l *icsLines) String() string {
+	return l.sb.String()
+}
@@ -0,0 +1,238 @@
+package roster
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func TestWrite_ICS(t *testing.T) {
+	// Thursday 20 Nov 2025
+	now := time.Date(2025, 11, 20, 9, 30, 0, 0, time.UTC)
+	colleagues := testColleagues(t)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, colleagues, FormatICS, now); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	out := buf.String()
+
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Error("expected every line to end in CRLF")
+	}
+	lines := unfoldICS(out)
+
+	blocks := map[string][]string{
+		"Asia/Kolkata": {
+			"BEGIN:VTIMEZONE",
+			"TZID:Asia/Kolkata",
+			"BEGIN:STANDARD",
+			"DTSTART:19700101T000000",
+			"TZOFFSETFROM:+0530",
+			"TZOFFSETTO:+0530",
+			"TZNAME:IST",
+			"END:STANDARD",
+			"END:VTIMEZONE",
+		},
+		"America/New_York": {
+			"BEGIN:VTIMEZONE",
+			"TZID:America/New_York",
+			"BEGIN:DAYLIGHT",
+			"DTSTART:20240310T020000",
+			"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU",
+			"TZOFFSETFROM:-0500",
+			"TZOFFSETTO:-0400",
+			"TZNAME:EDT",
+			"END:DAYLIGHT",
+			"BEGIN:STANDARD",
+			"DTSTART:20241103T020000",
+			"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU",
+			"TZOFFSETFROM:-0400",
+			"TZOFFSETTO:-0500",
+			"TZNAME:EST",
+			"END:STANDARD",
+			"END:VTIMEZONE",
+		},
+		"Priya": {
+			"BEGIN:VEVENT",
+			"UID:" + colleagues[0].ID + "@teamtime",
+			"DTSTAMP:20251120T093000Z",
+			"DTSTART;TZID=Asia/Kolkata:20251120T080000",
+			"DTEND;TZID=Asia/Kolkata:20251120T160000",
+			"RRULE:FREQ=WEEKLY;BYDAY=SU,MO,TU,WE,TH",
+			"SUMMARY:Priya working hours",
+			"LOCATION:Pune",
+			"CATEGORIES:backend,oncall",
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		},
+		"Bob": {
+			"BEGIN:VEVENT",
+			"UID:" + colleagues[2].ID + "@teamtime",
+			"DTSTAMP:20251120T093000Z",
+			"DTSTART;TZID=America/New_York:20251120T090000",
+			"DTEND;TZID=America/New_York:20251120T170000",
+			"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			`SUMMARY:Bob "the builder"\, Jr # 2 working hours`,
+			"LOCATION:New York | NY",
+			"CATEGORIES:team.ny",
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		},
+	}
+
+	for name, want := range blocks {
+		t.Run(name, func(t *testing.T) {
+			start := slices.Index(lines, want[1])
+			if start < 1 || lines[start-1] != want[0] {
+				t.Fatalf("%s not found in:\n%s", want[1], out)
+			}
+			got := lines[start-1 : min(start-1+len(want), len(lines))]
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
+		})
+	}
+
+	if got := strings.Count(out, "BEGIN:VTIMEZONE"); got != len(colleagues) {
+		t.Errorf("got %d timezones, want %d", got, len(colleagues))
+	}
+	if got := strings.Count(out, "BEGIN:VEVENT"); got != len(colleagues) {
+		t.Errorf("got %d events, want %d", got, len(colleagues))
+	}
+}
+
+func TestWrite_ICS_IrregularZone(t *testing.T) {
+	// Casablanca moves its clocks around Ramadan, which has no yearly rule
+	loc, err := time.LoadLocation("Africa/Casablanca")
+	if err != nil {
+		t.Skipf("no tz data for Africa/Casablanca: %v", err)
+	}
+	now := time.Date(2025, 11, 20, 9, 30, 0, 0, time.UTC)
+	if transitions := zoneTransitions(loc, now, now.AddDate(2, 0, 0)); len(transitions) == 0 {
+		t.Skip("tz data without transitions for Africa/Casablanca")
+	}
+
+	c, err := types.NewColleague("Youssef", "Casablanca", "Africa/Casablanca")
+	if err != nil {
+		t.Fatalf("failed to create colleague: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []types.Colleague{c}, FormatICS, now); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	// the transitions are listed until 2030, so the events stop there too
+	want := "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20300101T000000Z"
+	if lines := unfoldICS(buf.String()); !slices.Contains(lines, want) {
+		t.Errorf("expected %s in:\n%s", want, buf.String())
+	}
+}
+
+func TestWrite_ICS_AbolishedDST(t *testing.T) {
+	// São Paulo observed daylight saving time until February 2019
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("no tz data for America/Sao_Paulo: %v", err)
+	}
+	now := time.Date(2019, 6, 12, 9, 30, 0, 0, time.UTC)
+	if transitions := zoneTransitions(loc, now.AddDate(-1, 0, 0), now); len(transitions) == 0 {
+		t.Skip("tz data without transitions for America/Sao_Paulo")
+	}
+
+	c, err := types.NewColleague("Beatriz", "São Paulo", "America/Sao_Paulo")
+	if err != nil {
+		t.Fatalf("failed to create colleague: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []types.Colleague{c}, FormatICS, now); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	lines := unfoldICS(buf.String())
+
+	// the last observance holds for good, so the events do not end
+	want := "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+	if !slices.Contains(lines, want) {
+		t.Errorf("expected %s in:\n%s", want, buf.String())
+	}
+
+	// a plain observance for the end of daylight saving time, in force since
+	vtimezone := []string{
+		"BEGIN:VTIMEZONE",
+		"TZID:America/Sao_Paulo",
+		"BEGIN:STANDARD",
+		"DTSTART:20190217T000000",
+		"TZOFFSETFROM:-0200",
+		"TZOFFSETTO:-0300",
+		"TZNAME:-03",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+	}
+	start := slices.Index(lines, vtimezone[0])
+	if start < 0 {
+		t.Fatalf("no VTIMEZONE in:\n%s", buf.String())
+	}
+	got := lines[start:min(start+len(vtimezone), len(lines))]
+	if strings.Join(got, "\n") != strings.Join(vtimezone, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(vtimezone, "\n"))
+	}
+}
+
+func TestICSLines_Fold(t *testing.T) {
+	var l icsLines
+	value := strings.Repeat("ü", 100)
+	l.add("SUMMARY", value)
+
+	physical := strings.Split(strings.TrimSuffix(l.String(), "\r\n"), "\r\n")
+	if len(physical) < 3 {
+		t.Fatalf("expected a folded line, got %q", l.String())
+	}
+	for i, line := range physical {
+		if len(line) > icsLineLength {
+			t.Errorf("line %d is %d octets long", i+1, len(line))
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a character: %q", i+1, line)
+		}
+		if i > 0 && !strings.HasPrefix(line, " ") {
+			t.Errorf("continuation line %d does not start with a space", i+1)
+		}
+	}
+
+	if got := unfoldICS(l.String()); len(got) != 1 || got[0] != "SUMMARY:"+value {
+		t.Errorf("unfolding gave %q", got)
+	}
+}
+
+func TestICSOffset(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{seconds: 0, want: "+0000"},
+		{seconds: 19800, want: "+0530"},
+		{seconds: -18000, want: "-0500"},
+		{seconds: -(3600 + 1800 + 45), want: "-013045"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := icsOffset(tt.seconds); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// unfoldICS splits an iCalendar file into unfolded content lines
+func unfoldICS(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n ", "")
+	return strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n")
+}
@@ -0,0 +1,112 @@
+package roster
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// readJSON reads an array of objects. Besides strings, tags and work days may
+// be arrays and hours {"start": 9, "end": 17} objects, as in the colleagues
+// file itself
+func readJSON(data []byte) ([]record, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, jsonSyntaxError(data, dec, err)
+	}
+	if tok != json.Delim('[') {
+		return nil, &LineError{Line: lineAt(data, 0), Err: fmt.Errorf("%w: expected an array of colleagues", ErrSyntax)}
+	}
+
+	var records []record
+	for dec.More() {
+		line := lineAt(data, dec.InputOffset())
+
+		var object map[string]any
+		if err := dec.Decode(&object); err != nil {
+			return nil, jsonSyntaxError(data, dec, err)
+		}
+		if object == nil {
+			return nil, &LineError{Line: line, Err: fmt.Errorf("%w: expected an object", ErrSyntax)}
+		}
+
+		rec := record{line: line, fields: make(map[string]string, len(object))}
+		for name, value := range object {
+			text, err := jsonText(value)
+			if err != nil {
+				return nil, &LineError{Line: line, Err: fmt.Errorf("%s: %w", name, err)}
+			}
+			if err := rec.set(name, text); err != nil {
+				return nil, &LineError{Line: line, Err: err}
+			}
+		}
+		records = append(records, rec)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, jsonSyntaxError(data, dec, err)
+	}
+	return records, nil
+}
+
+// jsonText turns a decoded JSON value into the text form of a field
+func jsonText(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("%w: expected a list of strings", ErrSyntax)
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, ","), nil
+	case map[string]any:
+		start, okStart := v["start"].(json.Number)
+		end, okEnd := v["end"].(json.Number)
+		if !okStart || !okEnd || len(v) != 2 {
+			return "", fmt.Errorf(`%w: expected hours as {"start": 9, "end": 17}`, ErrSyntax)
+		}
+		return start.String() + "-" + end.String(), nil
+	default:
+		return "", fmt.Errorf("%w: unexpected value %v", ErrSyntax, v)
+	}
+}
+
+// jsonSyntaxError adds the line of a decoding error
+func jsonSyntaxError(data []byte, dec *json.Decoder, err error) error {
+	offset := dec.InputOffset()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	}
+	return &LineError{Line: lineOf(data, offset), Err: fmt.Errorf("%w: %v", ErrSyntax, err)}
+}
+
+// lineAt returns the line of the first value at or after offset, skipping
+// whitespace and the commas between array elements
+func lineAt(data []byte, offset int64) int {
+	offset = min(offset, int64(len(data)))
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+		offset++
+	}
+	return lineOf(data, offset)
+}
+
+// lineOf returns the line holding the byte at offset
+func lineOf(data []byte, offset int64) int {
+	offset = min(offset, int64(len(data)))
+	return 1 + bytes.Count(data[:offset], []byte("\n"))
+}
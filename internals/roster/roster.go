@@ -0,0 +1,276 @@
+package roster
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	ErrUnknownField  = errors.New("unknown field")
+	ErrDuplicateName = errors.New("duplicate name")
+	ErrSyntax        = errors.New("syntax error")
+)
+
+// Format names. Markdown tables and iCalendar files can be exported but not
+// imported, vCards imported but not exported
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatMarkdown = "markdown"
+	FormatVCard    = "vcard"
+	FormatICS      = "ics"
+)
+
+// Fields of a colleague in every format. Hours are ranges such as "9-17",
+// work days lists such as "mon-fri" and tags comma separated lists
+const (
+	FieldID            = "id"
+	FieldName          = "name"
+	FieldCity          = "city"
+	FieldTimezone      = "timezone"
+	FieldWorkHours     = "work_hours"
+	FieldExtendedHours = "extended_hours"
+	FieldWorkDays      = "work_days"
+	FieldTags          = "tags"
+)
+
+// Fields lists the fields in the order they are written
+var Fields = []string{FieldID, FieldName, FieldCity, FieldTimezone, FieldWorkHours, FieldExtendedHours, FieldWorkDays, FieldTags}
+
+var fieldAliases = map[string]string{
+	"tz":        FieldTimezone,
+	"time_zone": FieldTimezone,
+	"tag":       FieldTags,
+}
+
+// readers maps format names to their parser
+var readers = map[string]func(data []byte) ([]record, error){
+	FormatCSV:   readCSV,
+	FormatJSON:  readJSON,
+	FormatYAML:  readYAML,
+	FormatVCard: readVCard,
+}
+
+// Formats returns the names of the readable formats, sorted
+func Formats() []string {
+	names := make([]string, 0, len(readers))
+	for name := range readers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// extensionFormats maps the file extensions that differ from a format name
+var extensionFormats = map[string]string{
+	"yml": FormatYAML,
+	"md":  FormatMarkdown,
+	"vcf": FormatVCard,
+}
+
+// DetectFormat returns the format of path from its extension
+func DetectFormat(path string) (string, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if format, ok := extensionFormats[ext]; ok {
+		ext = format
+	}
+	_, readable := readers[ext]
+	_, writable := writers[ext]
+	if !readable && !writable {
+		names := append(Formats(), ExportFormats()...)
+		slices.Sort(names)
+		names = slices.Compact(names)
+		return "", fmt.Errorf("%w: cannot tell the format of %q from its extension, use %s", ErrUnknownFormat, path, strings.Join(names, ", "))
+	}
+	return ext, nil
+}
+
+// Entry is a colleague read from a file along with the line it starts on
+type Entry struct {
+	Line      int
+	Colleague types.Colleague
+	// IDGiven is set when the file gave the ID, which is otherwise generated
+	IDGiven bool
+}
+
+// LineError is a problem with the entry starting at Line
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// record is an entry as read from a file, before validation
+type record struct {
+	line   int
+	fields map[string]string
+}
+
+// TimezoneResolver returns the timezone of a colleague read without one, or
+// "" when it cannot tell
+type TimezoneResolver func(name, city string) (string, error)
+
+// ReadOption configures Read
+type ReadOption func(*readOptions)
+
+type readOptions struct {
+	resolveTimezone TimezoneResolver
+}
+
+// WithTimezoneResolver makes Read ask resolve for the timezone of entries
+// that have none, such as vCards without TZ. Its errors are reported as
+// errors of the entry
+func WithTimezoneResolver(resolve TimezoneResolver) ReadOption {
+	return func(o *readOptions) {
+		o.resolveTimezone = resolve
+	}
+}
+
+// Read parses colleagues from r in format and validates every entry. Entries
+// without an ID get a new one. A syntax error stops parsing; otherwise every
+// invalid entry is reported, each as a *LineError, joined into one error
+func Read(r io.Reader, format string, opts ...ReadOption) ([]Entry, error) {
+	var o readOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	read, ok := readers[format]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, use %s", ErrUnknownFormat, format, strings.Join(Formats(), ", "))
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := read(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	var errs []error
+	firstLine := make(map[string]int)
+	firstIDLine := make(map[string]int)
+	for _, rec := range records {
+		if rec.fields[FieldTimezone] == "" && o.resolveTimezone != nil {
+			tz, err := o.resolveTimezone(rec.fields[FieldName], rec.fields[FieldCity])
+			if err != nil {
+				errs = append(errs, &LineError{Line: rec.line, Err: err})
+				continue
+			}
+			rec.fields[FieldTimezone] = strings.TrimSpace(tz)
+		}
+
+		c, err := rec.colleague()
+		if err != nil {
+			errs = append(errs, &LineError{Line: rec.line, Err: err})
+			continue
+		}
+
+		key := strings.ToLower(c.Name)
+		if line, seen := firstLine[key]; seen {
+			errs = append(errs, &LineError{Line: rec.line, Err: fmt.Errorf("%w %q, also on line %d", ErrDuplicateName, c.Name, line)})
+			continue
+		}
+		firstLine[key] = rec.line
+
+		idGiven := strings.TrimSpace(rec.fields[FieldID]) != ""
+		if idGiven {
+			id := strings.ToLower(c.ID)
+			if line, seen := firstIDLine[id]; seen {
+				errs = append(errs, &LineError{Line: rec.line, Err: fmt.Errorf("%w: %q, also on line %d", types.ErrDuplicateID, c.ID, line)})
+				continue
+			}
+			firstIDLine[id] = rec.line
+		}
+		entries = append(entries, Entry{Line: rec.line, Colleague: c, IDGiven: idGiven})
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return entries, nil
+}
+
+// colleague builds and validates the colleague described by the record
+func (rec record) colleague() (types.Colleague, error) {
+	var opts []types.Option
+	for field, value := range rec.fields {
+		if value == "" {
+			continue
+		}
+
+		switch field {
+		case FieldWorkHours, FieldExtendedHours:
+			h, err := types.ParseHours(value)
+			if err != nil {
+				return types.Colleague{}, fmt.Errorf("%s: %w", field, err)
+			}
+			if field == FieldWorkHours {
+				opts = append(opts, types.WithWorkHours(h))
+			} else {
+				opts = append(opts, types.WithExtendedHours(h))
+			}
+		case FieldWorkDays:
+			d, err := types.ParseWeekdays(value)
+			if err != nil {
+				return types.Colleague{}, fmt.Errorf("%s: %w", field, err)
+			}
+			opts = append(opts, types.WithWorkDays(d))
+		case FieldTags:
+			t, err := types.ParseTags(value)
+			if err != nil {
+				return types.Colleague{}, fmt.Errorf("%s: %w", field, err)
+			}
+			opts = append(opts, types.WithTags(t))
+		}
+	}
+
+	if id := strings.TrimSpace(rec.fields[FieldID]); id != "" {
+		opts = append(opts, func(c *types.Colleague) { c.ID = id })
+	}
+
+	return types.NewColleague(rec.fields[FieldName], rec.fields[FieldCity], rec.fields[FieldTimezone], opts...)
+}
+
+// set stores a field value under its canonical name
+func (rec *record) set(name, value string) error {
+	field, err := canonicalField(name)
+	if err != nil {
+		return err
+	}
+	rec.fields[field] = strings.TrimSpace(value)
+	return nil
+}
+
+// canonicalField maps a field name as written in a file, e.g. "Work Hours" or
+// "tz", to its canonical name
+func canonicalField(name string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+	if alias, ok := fieldAliases[key]; ok {
+		return alias, nil
+	}
+	if !slices.Contains(Fields, key) {
+		return "", fmt.Errorf("%w %q, use %s", ErrUnknownField, name, strings.Join(Fields, ", "))
+	}
+	return key, nil
+}
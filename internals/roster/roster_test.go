@@ -0,0 +1,266 @@
+package roster
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr error
+	}{
+		{path: "team.csv", want: FormatCSV},
+		{path: "/tmp/Team.JSON", want: FormatJSON},
+		{path: "team.yaml", want: FormatYAML},
+		{path: "team.yml", want: FormatYAML},
+		{path: "TEAM.md", want: FormatMarkdown},
+		{path: "contacts.vcf", want: FormatVCard},
+		{path: "team.txt", wantErr: ErrUnknownFormat},
+		{path: "team", wantErr: ErrUnknownFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := DetectFormat(tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			input: "\ufeffName, City, TZ, Work Hours, Work Days, Tags\n" +
+				"Priya,Pune,Asia/Kolkata,8-16,sun-thu,\"backend,oncall\"\n" +
+				"\"Lúcio Bianchi\",Poggibonsi,Europe/Rome,,,\n",
+		},
+		{
+			name:   "json",
+			format: FormatJSON,
+			input: `[
+  {
+    "name": "Priya", "city": "Pune", "timezone": "Asia/Kolkata",
+    "work_hours": {"start": 8, "end": 16}, "work_days": ["sun", "mon", "tue", "wed", "thu"],
+    "tags": ["backend", "oncall"]
+  },
+  {"name": "Lúcio Bianchi", "city": "Poggibonsi", "tz": "Europe/Rome", "tags": null}
+]`,
+		},
+		{
+			name:   "yaml",
+			format: FormatYAML,
+			input: `# the team
+---
+- name: Priya   # lead
+  city: 'Pune'
+  timezone: "Asia/Kolkata"
+  work_hours: 8-16
+  work_days: sun-thu
+  tags:
+    - backend
+    - oncall
+-
+  name: Lúcio Bianchi
+  city: Poggibonsi
+  tz: Europe/Rome
+  tags: []
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Read(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(entries))
+			}
+
+			priya := entries[0].Colleague
+			if priya.Name != "Priya" || priya.City != "Pune" || priya.Timezone != "Asia/Kolkata" {
+				t.Errorf("got %+v", priya)
+			}
+			if priya.ID == "" {
+				t.Error("expected a generated ID")
+			}
+			if priya.WorkHours == nil || *priya.WorkHours != (types.Hours{Start: 8, End: 16}) {
+				t.Errorf("got work hours %v, want 8-16", priya.WorkHours)
+			}
+			if got := priya.WorkDays.String(); got != "sun,mon,tue,wed,thu" {
+				t.Errorf("got work days %q", got)
+			}
+			if got := priya.Tags.String(); got != "backend,oncall" {
+				t.Errorf("got tags %q", got)
+			}
+
+			lucio := entries[1].Colleague
+			if lucio.Name != "Lúcio Bianchi" || lucio.Timezone != "Europe/Rome" || lucio.Tags != nil {
+				t.Errorf("got %+v", lucio)
+			}
+		})
+	}
+}
+
+func TestRead_KeepsIDs(t *testing.T) {
+	entries, err := Read(strings.NewReader("id,name,city,timezone\nb7204e,Priya,Pune,Asia/Kolkata\n"), FormatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := entries[0].Colleague.ID; got != "b7204e" {
+		t.Errorf("got ID %q, want b7204e", got)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		input     string
+		wantErr   error
+		wantLines []int
+	}{
+		{
+			name:   "every invalid entry is reported",
+			format: FormatCSV,
+			input: "name,city,timezone,work_hours\n" +
+				"Priya,Pune,Asia/Kolkata,\n" +
+				"Bob,,America/New_York,\n" +
+				"Ana,Lisbon,Europe/Lisbon,25-3\n" +
+				"priya,Pune,Asia/Kolkata,\n",
+			wantErr:   ErrDuplicateName,
+			wantLines: []int{3, 4, 5},
+		},
+		{
+			name:   "duplicate id",
+			format: FormatCSV,
+			input: "id,name,city,timezone\n" +
+				"abc123,Priya,Pune,Asia/Kolkata\n" +
+				",Bob,NYC,America/New_York\n" +
+				"ABC123,Ana,Lisbon,Europe/Lisbon\n",
+			wantErr:   types.ErrDuplicateID,
+			wantLines: []int{4},
+		},
+		{
+			name:      "unknown csv column",
+			format:    FormatCSV,
+			input:     "name,city,timezone,email\n",
+			wantErr:   ErrUnknownField,
+			wantLines: []int{1},
+		},
+		{
+			name:      "missing json field",
+			format:    FormatJSON,
+			input:     "[\n  {\"name\": \"Priya\", \"city\": \"Pune\", \"timezone\": \"Asia/Kolkata\"},\n  {\"name\": \"Bob\", \"city\": \"NYC\"}\n]",
+			wantErr:   types.ErrMissingTimezone,
+			wantLines: []int{3},
+		},
+		{
+			name:      "json syntax",
+			format:    FormatJSON,
+			input:     "[\n  {\"name\": \"Priya\",}\n]",
+			wantErr:   ErrSyntax,
+			wantLines: []int{2},
+		},
+		{
+			name:      "json not an array",
+			format:    FormatJSON,
+			input:     `{"name": "Priya"}`,
+			wantErr:   ErrSyntax,
+			wantLines: []int{1},
+		},
+		{
+			name:      "yaml not a list",
+			format:    FormatYAML,
+			input:     "colleagues:\n  - name: Priya\n",
+			wantErr:   ErrSyntax,
+			wantLines: []int{1},
+		},
+		{
+			name:      "yaml bad indentation",
+			format:    FormatYAML,
+			input:     "- name: Priya\n  city: Pune\n   timezone: Asia/Kolkata\n",
+			wantErr:   ErrSyntax,
+			wantLines: []int{3},
+		},
+		{
+			name:      "yaml invalid entry",
+			format:    FormatYAML,
+			input:     "- name: Priya\n  city: Pune\n  timezone: Asia/Kolkata\n  tags: [Not A Tag]\n",
+			wantErr:   types.ErrInvalidTag,
+			wantLines: []int{1},
+		},
+		{
+			name:      "vcard without end",
+			format:    FormatVCard,
+			input:     "BEGIN:VCARD\nVERSION:4.0\nFN:Priya\n",
+			wantErr:   ErrSyntax,
+			wantLines: []int{1},
+		},
+		{
+			name:      "vcard without timezone",
+			format:    FormatVCard,
+			input:     "BEGIN:VCARD\nFN:Priya\nTZ:Asia/Kolkata\nADR:;;;Pune;;;\nEND:VCARD\nBEGIN:VCARD\nFN:Bob\nADR:;;;New York;;;\nTZ:-05:00\nEND:VCARD\n",
+			wantErr:   types.ErrMissingTimezone,
+			wantLines: []int{6},
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.input), tt.format)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			var lines []int
+			errs := []error{err}
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				errs = joined.Unwrap()
+			}
+			for _, e := range errs {
+				var lineErr *LineError
+				if errors.As(e, &lineErr) {
+					lines = append(lines, lineErr.Line)
+				}
+			}
+
+			if len(lines) != len(tt.wantLines) {
+				t.Fatalf("got lines %v, want %v (%v)", lines, tt.wantLines, err)
+			}
+			for i := range lines {
+				if lines[i] != tt.wantLines[i] {
+					t.Errorf("got lines %v, want %v", lines, tt.wantLines)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package roster
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// adrLocality is the index of the locality in an ADR value:
+// PO box;extended;street;locality;region;postal code;country
+const adrLocality = 3
+
+// vcardLine is an unfolded content line with the number of its first line
+type vcardLine struct {
+	num  int
+	text string
+}
+
+// vcardProperty is a content line such as ADR;TYPE=work:;;1 Main St;Pune;;;India
+type vcardProperty struct {
+	name   string
+	params string
+	value  string
+}
+
+// readVCard reads vCard 3.0 and 4.0 contacts: FN, or N when there is no FN,
+// becomes the name, the locality of the work address, or of the first
+// address, the city, and TZ the timezone when it is a tz database name.
+// Other properties are ignored
+func readVCard(data []byte) ([]record, error) {
+	var records []record
+	var card *record
+	var adr, workAdr, n string
+
+	for _, line := range unfoldVCard(string(data)) {
+		if strings.TrimSpace(line.text) == "" {
+			continue
+		}
+
+		p, err := parseVCardProperty(line.text)
+		if err != nil {
+			return nil, &LineError{Line: line.num, Err: err}
+		}
+
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VCARD"):
+			if card != nil {
+				return nil, &LineError{Line: line.num, Err: fmt.Errorf("%w: BEGIN:VCARD inside a vCard", ErrSyntax)}
+			}
+			card = &record{line: line.num, fields: make(map[string]string)}
+			adr, workAdr, n = "", "", ""
+		case card == nil:
+			return nil, &LineError{Line: line.num, Err: fmt.Errorf("%w: %s outside a vCard", ErrSyntax, p.name)}
+		case p.name == "END" && strings.EqualFold(p.value, "VCARD"):
+			if card.fields[FieldName] == "" {
+				card.fields[FieldName] = n
+			}
+			if workAdr != "" {
+				adr = workAdr
+			}
+			card.fields[FieldCity] = adr
+			records = append(records, *card)
+			card = nil
+		case p.name == "FN":
+			card.fields[FieldName] = unescapeVCard(p.value)
+		case p.name == "N":
+			n = vcardName(p.value)
+		case p.name == "ADR":
+			locality := vcardComponent(p.value, adrLocality)
+			if adr == "" {
+				adr = locality
+			}
+			if workAdr == "" && vcardHasType(p.params, "work") {
+				workAdr = locality
+			}
+		case p.name == "TZ":
+			// TZ may also be a UTC offset or a URI, which do not name a timezone
+			tz := unescapeVCard(p.value)
+			if _, err := time.LoadLocation(tz); err == nil && tz != "" && tz != "UTC" && tz != "Local" {
+				card.fields[FieldTimezone] = tz
+			}
+		}
+	}
+
+	if card != nil {
+		return nil, &LineError{Line: card.line, Err: fmt.Errorf("%w: vCard without END:VCARD", ErrSyntax)}
+	}
+	return records, nil
+}
+
+// unfoldVCard joins the lines that continue on the next one, which starts
+// with a space or a tab, keeping the number of the first line
+func unfoldVCard(data string) []vcardLine {
+	var lines []vcardLine
+	for i, text := range strings.Split(strings.TrimPrefix(data, "\ufeff"), "\n") {
+		text = strings.TrimSuffix(text, "\r")
+		if len(lines) > 0 && (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		lines = append(lines, vcardLine{num: i + 1, text: text})
+	}
+	return lines
+}
+
+// parseVCardProperty splits [group.]NAME[;params]:value, ignoring the colons
+// inside quoted parameter values
+func parseVCardProperty(line string) (vcardProperty, error) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon == -1 {
+		return vcardProperty{}, fmt.Errorf("%w: expected NAME:value", ErrSyntax)
+	}
+
+	name, params, _ := strings.Cut(line[:colon], ";")
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+	return vcardProperty{name: strings.ToUpper(strings.TrimSpace(name)), params: params, value: line[colon+1:]}, nil
+}
+
+// vcardHasType reports whether params include the TYPE, e.g. TYPE=work or
+// TYPE="home,work", or a vCard 2.1 style bare WORK parameter
+func vcardHasType(params, want string) bool {
+	for _, param := range strings.Split(params, ";") {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			value = key
+		} else if !strings.EqualFold(key, "TYPE") {
+			continue
+		}
+		for _, t := range strings.Split(strings.Trim(value, `"`), ",") {
+			if strings.EqualFold(strings.TrimSpace(t), want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// vcardComponent returns the component at idx of a structured value such as
+// ADR, or "" when it is missing
+func vcardComponent(value string, idx int) string {
+	components := splitVCard(value, ';')
+	if idx >= len(components) {
+		return ""
+	}
+	// a component may hold several values separated by commas
+	first, _, _ := cutVCard(components[idx], ',')
+	return strings.TrimSpace(unescapeVCard(first))
+}
+
+// vcardName turns N:Family;Given;Additional;Prefix;Suffix into "Given Family"
+func vcardName(value string) string {
+	components := splitVCard(value, ';')
+	var parts []string
+	for _, idx := range []int{1, 0} {
+		if idx < len(components) {
+			if part := strings.TrimSpace(unescapeVCard(components[idx])); part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// splitVCard splits value on the unescaped occurrences of sep
+func splitVCard(value string, sep byte) []string {
+	var parts []string
+	for {
+		part, rest, found := cutVCard(value, sep)
+		parts = append(parts, part)
+		if !found {
+			return parts
+		}
+		value = rest
+	}
+}
+
+// cutVCard cuts value around the first unescaped sep
+func cutVCard(value string, sep byte) (string, string, bool) {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case sep:
+			return value[:i], value[i+1:], true
+		}
+	}
+	return value, "", false
+}
+
+// unescapeVCard resolves the \\, \, \; and \n escapes of text values
+func unescapeVCard(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			sb.WriteByte(' ')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
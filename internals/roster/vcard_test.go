@@ -0,0 +1,93 @@
+package roster
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRead_VCard(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"N:Bianchi;Lúcio;;;",
+		"FN:Lúcio Bianchi",
+		"ADR;TYPE=home:;;Via Roma 1;Siena;;53100;Italy",
+		"ADR;TYPE=WORK,pref:;;Piazza Cavour\\, 3;Poggi",
+		" bonsi;;53036;Italy",
+		"TZ:Europe/Rome",
+		"EMAIL:lucio@example.com",
+		"END:VCARD",
+		"",
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"N:Sharma;Priya;;;",
+		`item1.ADR;LABEL="Office: Pune":;;;Pune;MH;;India`,
+		"TZ;VALUE=text:Asia/Kolkata",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"FN:Ana",
+		"ADR:;;;Lisbon;;;",
+		"TZ;VALUE=utc-offset:+0100",
+		"END:VCARD",
+	}, "\r\n")
+
+	resolved := map[string]string{}
+	resolve := func(name, city string) (string, error) {
+		resolved[name] = city
+		return "Europe/Lisbon", nil
+	}
+
+	entries, err := Read(strings.NewReader(input), FormatVCard, WithTimezoneResolver(resolve))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		line     int
+		name     string
+		city     string
+		timezone string
+	}{
+		{line: 1, name: "Lúcio Bianchi", city: "Poggibonsi", timezone: "Europe/Rome"},
+		{line: 12, name: "Priya Sharma", city: "Pune", timezone: "Asia/Kolkata"},
+		{line: 18, name: "Ana", city: "Lisbon", timezone: "Europe/Lisbon"},
+	}
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := entries[i]
+			if e.Line != tt.line {
+				t.Errorf("got line %d, want %d", e.Line, tt.line)
+			}
+			c := e.Colleague
+			if c.Name != tt.name || c.City != tt.city || c.Timezone != tt.timezone {
+				t.Errorf("got %q, %q, %q, want %q, %q, %q", c.Name, c.City, c.Timezone, tt.name, tt.city, tt.timezone)
+			}
+		})
+	}
+
+	if len(resolved) != 1 || resolved["Ana"] != "Lisbon" {
+		t.Errorf("resolver called with %v, want only Ana in Lisbon", resolved)
+	}
+}
+
+func TestRead_TimezoneResolverError(t *testing.T) {
+	errNoAnswer := errors.New("no answer")
+	input := "BEGIN:VCARD\nFN:Ana\nEND:VCARD\n"
+
+	_, err := Read(strings.NewReader(input), FormatVCard, WithTimezoneResolver(func(string, string) (string, error) {
+		return "", errNoAnswer
+	}))
+	if !errors.Is(err, errNoAnswer) {
+		t.Fatalf("expected %v, got %v", errNoAnswer, err)
+	}
+
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 1 {
+		t.Errorf("expected an error on line 1, got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package roster
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// yamlLine is a line of YAML without its indentation and comment
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// readYAML reads a sequence of mappings, the subset of YAML written by
+// export and by hand:
+//
+//   - name: Priya
+//     city: Pune
+//     timezone: Asia/Kolkata
+//     tags: [backend, oncall]
+//
+// Values may be plain, single or double quoted, and lists may also be
+// written as indented "- item" lines
+func readYAML(data []byte) ([]record, error) {
+	lines, err := yamlLines(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 1 && lines[0].text == "[]" {
+		return nil, nil
+	}
+
+	var records []record
+	for i := 0; i < len(lines); {
+		item := lines[i]
+		if item.text != "-" && !strings.HasPrefix(item.text, "- ") {
+			return nil, yamlError(item, "expected a colleague starting with '- '")
+		}
+
+		rec := record{line: item.num, fields: make(map[string]string)}
+		i++
+
+		keyIndent := -1
+		if first := strings.TrimLeft(item.text[1:], " "); first != "" {
+			pair := yamlLine{num: item.num, indent: item.indent + len(item.text) - len(first), text: first}
+			keyIndent = pair.indent
+			if i, err = readYAMLPair(&rec, lines, i, pair); err != nil {
+				return nil, err
+			}
+		}
+
+		for i < len(lines) && lines[i].indent > item.indent {
+			if keyIndent == -1 {
+				keyIndent = lines[i].indent
+			}
+			if lines[i].indent != keyIndent {
+				return nil, yamlError(lines[i], "unexpected indentation")
+			}
+			if i, err = readYAMLPair(&rec, lines, i+1, lines[i]); err != nil {
+				return nil, err
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// readYAMLPair stores the "key: value" pair and, when the value is empty, the
+// list items that follow it. It returns the index of the next line to read
+func readYAMLPair(rec *record, lines []yamlLine, next int, pair yamlLine) (int, error) {
+	key, value, ok := strings.Cut(pair.text, ":")
+	if !ok || strings.TrimSpace(key) == "" {
+		return 0, yamlError(pair, "expected key: value")
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		var items []string
+		for next < len(lines) && lines[next].indent >= pair.indent && strings.HasPrefix(lines[next].text, "- ") {
+			item, err := yamlScalar(strings.TrimSpace(lines[next].text[2:]))
+			if err != nil {
+				return 0, yamlError(lines[next], err.Error())
+			}
+			items = append(items, item)
+			next++
+		}
+		value = strings.Join(items, ",")
+	} else {
+		var err error
+		if value, err = yamlValue(value); err != nil {
+			return 0, yamlError(pair, err.Error())
+		}
+	}
+
+	if err := rec.set(key, value); err != nil {
+		return 0, &LineError{Line: pair.num, Err: err}
+	}
+	return next, nil
+}
+
+// yamlValue parses a scalar or a [flow, list], returning lists comma separated
+func yamlValue(s string) (string, error) {
+	if !strings.HasPrefix(s, "[") {
+		return yamlScalar(s)
+	}
+	if !strings.HasSuffix(s, "]") {
+		return "", fmt.Errorf("unterminated list %s", s)
+	}
+
+	var items []string
+	for _, part := range splitFlow(s[1 : len(s)-1]) {
+		item, err := yamlScalar(strings.TrimSpace(part))
+		if err != nil {
+			return "", err
+		}
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return strings.Join(items, ","), nil
+}
+
+// yamlScalar parses a plain, 'single' or "double" quoted scalar
+func yamlScalar(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		var v string
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return "", fmt.Errorf("invalid double quoted value %s", s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("invalid single quoted value %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case strings.HasPrefix(s, "{"):
+		return "", fmt.Errorf("nested mappings are not supported, write hours as 9-17")
+	case s == "~" || s == "null":
+		return "", nil
+	default:
+		return s, nil
+	}
+}
+
+// splitFlow splits the inside of a flow list on the commas outside quotes
+func splitFlow(s string) []string {
+	var parts []string
+	start := 0
+	scanYAML(s, func(i int, r rune) bool {
+		if r == ',' {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+		return true
+	})
+	return append(parts, s[start:])
+}
+
+// scanYAML calls fn for each rune of s outside quoted scalars until fn returns
+// false. A quote only opens a scalar at the start of a value, so that the
+// apostrophe in a plain O'Brien is just a character
+func scanYAML(s string, fn func(i int, r rune) bool) {
+	var quote rune
+	escaped := false
+	prev := ' '
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case (r == '"' || r == '\'') && strings.ContainsRune(" :-[,", prev):
+			quote = r
+		default:
+			if !fn(i, r) {
+				return
+			}
+		}
+		prev = r
+	}
+}
+
+// yamlLines splits data into lines, dropping blank lines, comments and
+// document markers
+func yamlLines(data string) ([]yamlLine, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.TrimPrefix(data, "\ufeff"), "\n") {
+		text := strings.TrimRight(stripYAMLComment(raw), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+
+		line := yamlLine{num: i + 1, indent: len(text) - len(trimmed), text: trimmed}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, yamlError(line, "tabs are not allowed for indentation")
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// stripYAMLComment removes a '#' comment that starts a line or follows a space,
+// outside quotes
+func stripYAMLComment(s string) string {
+	end := len(s)
+	scanYAML(s, func(i int, r rune) bool {
+		if r == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t') {
+			end = i
+			return false
+		}
+		return true
+	})
+	return s[:end]
+}
+
+func yamlError(line yamlLine, msg string) error {
+	return &LineError{Line: line.num, Err: fmt.Errorf("%w: %s", ErrSyntax, msg)}
+}
@@ -0,0 +1,119 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+// planStep is the granularity of candidate meeting start times. Working hours
+// start on the hour and every timezone offset is a multiple of 15 minutes, so
+// sampling at this step never misses a change of availability
+const planStep = 15 * time.Minute
+
+var (
+	ErrNoParticipants  = errors.New("no participants")
+	ErrInvalidDuration = errors.New("meeting duration must be between 15 minutes and 24 hours")
+)
+
+// Window is a range of meeting start times sharing the same availability
+type Window struct {
+	// Start is the earliest time the meeting can start
+	Start time.Time
+	// End is the time the meeting ends when started at the latest start time
+	End time.Time
+	// Extended lists participants who would be in their extended hours
+	Extended []types.Colleague
+	// Off lists participants who would be off hours or on a day off
+	Off []types.Colleague
+}
+
+// Perfect reports whether everyone is within working hours for the whole window
+func (w Window) Perfect() bool {
+	return len(w.Extended) == 0 && len(w.Off) == 0
+}
+
+// FindWindows returns the meeting windows on the given day, ranked from best
+// to worst: fewest participants off hours, then fewest in extended hours, then
+// earliest start. day is interpreted as a calendar date in its own location
+func FindWindows(colleagues []types.Colleague, day time.Time, duration time.Duration) ([]Window, error) {
+	if len(colleagues) == 0 {
+		return nil, ErrNoParticipants
+	}
+
+	if duration < planStep || duration > 24*time.Hour {
+		return nil, ErrInvalidDuration
+	}
+
+	locations := make([]*time.Location, len(colleagues))
+	for i, c := range colleagues {
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("colleague %s: %w", c.Name, err)
+		}
+		locations[i] = loc
+	}
+
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	var windows []Window
+	var lastKey string
+	for start := dayStart; start.Before(dayEnd); start = start.Add(planStep) {
+		slot, key := evaluateSlot(colleagues, locations, start, duration)
+
+		if len(windows) > 0 && key == lastKey {
+			windows[len(windows)-1].End = slot.End
+			continue
+		}
+
+		windows = append(windows, slot)
+		lastKey = key
+	}
+
+	slices.SortStableFunc(windows, func(a, b Window) int {
+		if len(a.Off) != len(b.Off) {
+			return len(a.Off) - len(b.Off)
+		}
+		if len(a.Extended) != len(b.Extended) {
+			return len(a.Extended) - len(b.Extended)
+		}
+		return a.Start.Compare(b.Start)
+	})
+
+	return windows, nil
+}
+
+// evaluateSlot classifies every participant by their least available moment
+// during a meeting starting at start. The returned key identifies the
+// combination of statuses so that adjacent slots can be merged
+func evaluateSlot(colleagues []types.Colleague, locations []*time.Location, start time.Time, duration time.Duration) (Window, string) {
+	end := start.Add(duration)
+	w := Window{Start: start, End: end}
+	var key strings.Builder
+
+	for i, c := range colleagues {
+		worst := StatusWork
+		for t := start; t.Before(end); t = t.Add(planStep) {
+			status := Classify(t.In(locations[i]), c)
+			if status.severity() > worst.severity() {
+				worst = status
+			}
+		}
+
+		switch worst {
+		case StatusWork:
+		case StatusExtended:
+			w.Extended = append(w.Extended, c)
+		default:
+			w.Off = append(w.Off, c)
+		}
+		fmt.Fprintf(&key, "%d", worst.severity())
+	}
+
+	return w, key.String()
+}
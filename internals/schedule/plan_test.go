@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func TestFindWindows(t *testing.T) {
+	london := mustLocation(t, "Europe/London")
+	thursday := time.Date(2025, 11, 20, 0, 0, 0, 0, london)
+
+	t.Run("perfect overlap", func(t *testing.T) {
+		colleagues := []types.Colleague{
+			{Name: "Alice", City: "London", Timezone: "Europe/London"},
+			{Name: "Lucio", City: "Poggibonsi", Timezone: "Europe/Rome"},
+		}
+
+		windows, err := FindWindows(colleagues, thursday, time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		best := windows[0]
+		if !best.Perfect() {
+			t.Fatalf("expected a perfect window, got %+v", best)
+		}
+
+		// Rome is an hour ahead, so the shared window is 09:00-16:00 London time
+		assertClock(t, best.Start, london, "09:00")
+		assertClock(t, best.End, london, "16:00")
+	})
+
+	t.Run("no perfect overlap ranks by extended hours", func(t *testing.T) {
+		colleagues := []types.Colleague{
+			{Name: "Alice", City: "London", Timezone: "Europe/London"},
+			{Name: "Bob", City: "San Francisco", Timezone: "America/Los_Angeles"},
+		}
+
+		windows, err := FindWindows(colleagues, thursday, time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		best := windows[0]
+		if best.Perfect() {
+			t.Fatalf("did not expect a perfect window, got %+v", best)
+		}
+
+		if len(best.Off) != 0 {
+			t.Errorf("expected nobody off hours, got %d", len(best.Off))
+		}
+
+		if len(best.Extended) != 1 {
+			t.Errorf("expected one colleague in extended hours, got %d", len(best.Extended))
+		}
+
+		for i := 1; i < len(windows); i++ {
+			prev, cur := windows[i-1], windows[i]
+			if len(prev.Off) > len(cur.Off) {
+				t.Fatalf("windows not ranked by off hours at %d", i)
+			}
+		}
+	})
+
+	t.Run("custom working hours", func(t *testing.T) {
+		colleagues := []types.Colleague{
+			{Name: "Alice", City: "London", Timezone: "Europe/London", WorkHours: &types.Hours{Start: 8, End: 16}},
+			{Name: "Carla", City: "London", Timezone: "Europe/London", WorkHours: &types.Hours{Start: 12, End: 20}},
+		}
+
+		windows, err := FindWindows(colleagues, thursday, 30*time.Minute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		assertClock(t, windows[0].Start, london, "12:00")
+		assertClock(t, windows[0].End, london, "16:00")
+	})
+
+	t.Run("weekend has no working window", func(t *testing.T) {
+		colleagues := []types.Colleague{
+			{Name: "Alice", City: "London", Timezone: "Europe/London"},
+		}
+		saturday := time.Date(2025, 11, 22, 0, 0, 0, 0, london)
+
+		windows, err := FindWindows(colleagues, saturday, time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(windows) != 1 || len(windows[0].Off) != 1 {
+			t.Errorf("expected a single window with everyone off, got %+v", windows)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		colleagues := []types.Colleague{{Name: "Alice", City: "London", Timezone: "Europe/London"}}
+
+		if _, err := FindWindows(nil, thursday, time.Hour); !errors.Is(err, ErrNoParticipants) {
+			t.Errorf("expected %v, got %v", ErrNoParticipants, err)
+		}
+
+		if _, err := FindWindows(colleagues, thursday, time.Minute); !errors.Is(err, ErrInvalidDuration) {
+			t.Errorf("expected %v, got %v", ErrInvalidDuration, err)
+		}
+	})
+}
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("failed to load location %s: %v", name, err)
+	}
+	return loc
+}
+
+func assertClock(t *testing.T, got time.Time, loc *time.Location, want string) {
+	t.Helper()
+	if clock := got.In(loc).Format("15:04"); clock != want {
+		t.Errorf("got %s, want %s", clock, want)
+	}
+}
@@ -0,0 +1,135 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+// Status describes a colleague's availability at a given moment
+type Status string
+
+const (
+	StatusWork     Status = "work"
+	StatusExtended Status = "extended"
+	StatusOff      Status = "off"
+	StatusDayOff   Status = "day off"
+)
+
+// severity orders statuses from most to least available
+func (s Status) severity() int {
+	switch s {
+	case StatusWork:
+		return 0
+	case StatusExtended:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// ClassifyHour classifies an hour of the day against a colleague's working and extended hours
+func ClassifyHour(hour int, work, extended types.Hours) Status {
+	if work.Contains(hour) {
+		return StatusWork
+	}
+
+	if extended.Contains(hour) {
+		return StatusExtended
+	}
+
+	return StatusOff
+}
+
+// Classify returns the colleague's availability at localTime, which must
+// already be expressed in the colleague's timezone
+func Classify(localTime time.Time, c types.Colleague) Status {
+	if !c.EffectiveWorkDays().Contains(localTime.Weekday()) {
+		return StatusDayOff
+	}
+
+	return ClassifyHour(localTime.Hour(), c.EffectiveWorkHours(), c.EffectiveExtendedHours())
+}
+
+// At returns the colleague's local time and availability at the instant t
+func At(t time.Time, c types.Colleague) (time.Time, Status, error) {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	local := t.In(loc)
+	return local, Classify(local, c), nil
+}
+
+// Span is a stretch of time over which a colleague's availability does not
+// change, with Start and End in the colleague's timezone
+type Span struct {
+	Start  time.Time
+	End    time.Time
+	Status Status
+}
+
+// Spans splits the range from start to end into the colleague's successive
+// availabilities, e.g. work until 17:00 then extended until the end
+func Spans(start, end time.Time, c types.Colleague) ([]Span, error) {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end = start.In(loc), end.In(loc)
+	var spans []Span
+	for t := start; t.Before(end); t = t.Add(planStep) {
+		status := Classify(t, c)
+		if len(spans) > 0 && spans[len(spans)-1].Status == status {
+			continue
+		}
+		if len(spans) > 0 {
+			spans[len(spans)-1].End = t
+		}
+		spans = append(spans, Span{Start: t, Status: status})
+	}
+
+	if len(spans) > 0 {
+		spans[len(spans)-1].End = end
+	}
+	return spans, nil
+}
+
+// maxLookahead bounds the search for the next change of availability, enough
+// to cover a full week of days off
+const maxLookahead = 8 * 24
+
+// UntilWorkStart returns how long until the colleague's next working hours
+// start, or zero if they are working at localTime
+func UntilWorkStart(localTime time.Time, c types.Colleague) time.Duration {
+	if Classify(localTime, c) == StatusWork {
+		return 0
+	}
+	return untilNext(localTime, c, func(s Status) bool { return s == StatusWork })
+}
+
+// UntilWorkEnd returns how long until the colleague's current working hours
+// end, or zero if they are not working at localTime
+func UntilWorkEnd(localTime time.Time, c types.Colleague) time.Duration {
+	if Classify(localTime, c) != StatusWork {
+		return 0
+	}
+	return untilNext(localTime, c, func(s Status) bool { return s != StatusWork })
+}
+
+// untilNext returns the time from localTime to the first following hour at
+// which match reports true, or zero if there is none within maxLookahead
+func untilNext(localTime time.Time, c types.Colleague, match func(Status) bool) time.Duration {
+	// Truncate on the wall clock: time.Truncate works on absolute time and
+	// would land on the half hour in zones such as Asia/Kolkata
+	hour := time.Date(localTime.Year(), localTime.Month(), localTime.Day(), localTime.Hour(), 0, 0, 0, localTime.Location())
+	for i := 1; i <= maxLookahead; i++ {
+		t := hour.Add(time.Duration(i) * time.Hour)
+		if match(Classify(t, c)) {
+			return t.Sub(localTime)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,246 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func TestClassifyHour(t *testing.T) {
+	tests := []struct {
+		name     string
+		hour     int
+		work     *types.Hours
+		extended *types.Hours
+		want     Status
+	}{
+		{
+			name: "off too late evening",
+			hour: 3,
+			want: StatusOff,
+		},
+		{
+			name: "off too early morning",
+			hour: 23,
+			want: StatusOff,
+		},
+		{
+			name: "work time",
+			hour: 9,
+			want: StatusWork,
+		},
+		{
+			name: "extended work time morning",
+			hour: 7,
+			want: StatusExtended,
+		},
+		{
+			name: "extended work time afternoon",
+			hour: 17,
+			want: StatusExtended,
+		},
+		{
+			name: "custom work hours start early",
+			hour: 8,
+			work: &types.Hours{Start: 8, End: 16},
+			want: StatusWork,
+		},
+		{
+			name: "custom work hours end early",
+			hour: 16,
+			work: &types.Hours{Start: 8, End: 16},
+			want: StatusExtended,
+		},
+		{
+			name: "late shift widens default extended hours",
+			hour: 20,
+			work: &types.Hours{Start: 12, End: 21},
+			want: StatusWork,
+		},
+		{
+			name:     "custom extended hours",
+			hour:     21,
+			work:     &types.Hours{Start: 12, End: 20},
+			extended: &types.Hours{Start: 10, End: 22},
+			want:     StatusExtended,
+		},
+		{
+			name:     "outside custom extended hours",
+			hour:     8,
+			work:     &types.Hours{Start: 12, End: 20},
+			extended: &types.Hours{Start: 10, End: 22},
+			want:     StatusOff,
+		},
+	}
+
+	for _, tt := range tests {
+		c := types.Colleague{WorkHours: tt.work, ExtendedHours: tt.extended}
+		got := ClassifyHour(tt.hour, c.EffectiveWorkHours(), c.EffectiveExtendedHours())
+		if got != tt.want {
+			t.Errorf("got %q, want %q, given %v", got, tt.want, tt.hour)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	sunToThu := types.Weekdays{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday}
+	tests := []struct {
+		name     string
+		time     time.Time
+		workDays types.Weekdays
+		want     Status
+	}{
+		{
+			name: "weekday work hours",
+			time: time.Date(2025, 12, 5, 10, 0, 0, 0, time.UTC),
+			want: StatusWork,
+		},
+		{
+			name: "saturday during work hours",
+			time: time.Date(2025, 12, 6, 10, 0, 0, 0, time.UTC),
+			want: StatusDayOff,
+		},
+		{
+			name:     "friday with sunday to thursday week",
+			time:     time.Date(2025, 12, 5, 10, 0, 0, 0, time.UTC),
+			workDays: sunToThu,
+			want:     StatusDayOff,
+		},
+		{
+			name:     "sunday with sunday to thursday week",
+			time:     time.Date(2025, 12, 7, 18, 0, 0, 0, time.UTC),
+			workDays: sunToThu,
+			want:     StatusExtended,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.time, types.Colleague{WorkDays: tt.workDays})
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAt(t *testing.T) {
+	instant := time.Date(2025, 12, 5, 9, 30, 0, 0, time.UTC)
+
+	local, status, err := At(instant, types.Colleague{Timezone: "Asia/Kolkata"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := local.Format("15:04"); got != "15:00" {
+		t.Errorf("got %s, want 15:00", got)
+	}
+
+	if status != StatusWork {
+		t.Errorf("got %q, want %q", status, StatusWork)
+	}
+
+	if _, _, err := At(instant, types.Colleague{Timezone: "Mars/Olympus"}); err == nil {
+		t.Error("expected error for invalid timezone")
+	}
+}
+
+func TestSpans(t *testing.T) {
+	// 15:00 to 18:30 in Kolkata on a Friday, then the weekend
+	friday := time.Date(2025, 12, 5, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  string
+	}{
+		{name: "within working hours", start: friday, end: friday.Add(time.Hour), want: "work 15:00-16:00"},
+		{name: "into extended hours", start: friday, end: friday.Add(3 * time.Hour), want: "work 15:00-17:00, extended 17:00-18:00"},
+		{
+			name:  "into the weekend",
+			start: friday.Add(4 * time.Hour),
+			end:   friday.Add(10 * time.Hour),
+			want:  "extended 19:00-20:00, off 20:00-00:00, day off 00:00-01:00",
+		},
+		{name: "empty range", start: friday, end: friday, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans, err := Spans(tt.start, tt.end, types.Colleague{Timezone: "Asia/Kolkata"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			parts := make([]string, len(spans))
+			for i, s := range spans {
+				parts[i] = fmt.Sprintf("%s %s-%s", s.Status, s.Start.Format("15:04"), s.End.Format("15:04"))
+			}
+			if got := strings.Join(parts, ", "); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Spans(friday, friday.Add(time.Hour), types.Colleague{Timezone: "Mars/Olympus"}); err == nil {
+		t.Error("expected error for invalid timezone")
+	}
+}
+
+func TestUntilWork(t *testing.T) {
+	tests := []struct {
+		name      string
+		time      time.Time
+		wantStart time.Duration
+		wantEnd   time.Duration
+	}{
+		{
+			name:      "during work hours",
+			time:      time.Date(2025, 12, 5, 15, 30, 0, 0, time.UTC),
+			wantStart: 0,
+			wantEnd:   90 * time.Minute,
+		},
+		{
+			name:      "early morning",
+			time:      time.Date(2025, 12, 4, 7, 45, 0, 0, time.UTC),
+			wantStart: 75 * time.Minute,
+			wantEnd:   0,
+		},
+		{
+			name:      "friday evening waits for monday",
+			time:      time.Date(2025, 12, 5, 18, 0, 0, 0, time.UTC),
+			wantStart: 63 * time.Hour,
+			wantEnd:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := types.Colleague{}
+
+			if got := UntilWorkStart(tt.time, c); got != tt.wantStart {
+				t.Errorf("until start: got %v, want %v", got, tt.wantStart)
+			}
+
+			if got := UntilWorkEnd(tt.time, c); got != tt.wantEnd {
+				t.Errorf("until end: got %v, want %v", got, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestUntilWork_HalfHourOffset(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	local := time.Date(2025, 11, 20, 15, 0, 0, 0, kolkata)
+	if got := UntilWorkEnd(local, types.Colleague{}); got != 2*time.Hour {
+		t.Errorf("got %v, want %v", got, 2*time.Hour)
+	}
+}
@@ -2,7 +2,7 @@ package service
 
 import (
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/matteo-gildone/teamtime/internals/storage"
 	"github.com/matteo-gildone/teamtime/internals/types"
@@ -18,42 +18,79 @@ func NewColleagueService(m *storage.Manager) *ColleagueService {
 	}
 }
 
-func (s *ColleagueService) AddColleague(name, city, tz string) (types.Colleague, error) {
-	cl, err := s.manager.Load()
-	if err != nil {
-		return types.Colleague{}, fmt.Errorf("failed to load colleagues: %w", err)
-	}
-
-	colleague, err := types.NewColleague(name, city, tz)
+func (s *ColleagueService) AddColleague(name, city, tz string, opts ...types.Option) (types.Colleague, error) {
+	colleague, err := types.NewColleague(name, city, tz, opts...)
 	if err != nil {
 		return types.Colleague{}, fmt.Errorf("invalid colleague data: %w", err)
 	}
 
-	cl.Add(colleague)
-
-	if err := s.manager.Save(cl); err != nil {
-		return types.Colleague{}, fmt.Errorf("colleague added to list but failed to save: %w", err)
+	err = s.manager.Record(func(cl *types.ColleagueList) (storage.Change, error) {
+		if err := cl.Add(colleague); err != nil {
+			return storage.Change{}, err
+		}
+		// Add assigns a new ID if the generated one is already taken
+		colleague = (*cl)[len(*cl)-1]
+		return storage.Change{Op: storage.OpAdd, Position: len(*cl), After: &colleague}, nil
+	})
+	if err != nil {
+		return types.Colleague{}, err
 	}
 
 	return colleague, nil
 }
 
-func (s *ColleagueService) RemoveColleague(idx int) (types.Colleague, error) {
-	cl, err := s.manager.Load()
+// RemoveColleague removes the colleague referenced by ref, an ID or an exact name
+func (s *ColleagueService) RemoveColleague(ref string) (types.Colleague, error) {
+	var removed types.Colleague
+	err := s.manager.Record(func(cl *types.ColleagueList) (storage.Change, error) {
+		idx, err := cl.IndexOf(ref)
+		if err != nil {
+			return storage.Change{}, fmt.Errorf("failed to find colleague: %w", suggestionError(err, *cl, ref))
+		}
+
+		removed, err = cl.Remove(idx)
+		if err != nil {
+			return storage.Change{}, fmt.Errorf("failed to remove colleagues: %w", err)
+		}
+		return storage.Change{Op: storage.OpRemove, Position: idx, Before: &removed}, nil
+	})
 	if err != nil {
-		return types.Colleague{}, fmt.Errorf("failed to load colleagues: %w", err)
+		return types.Colleague{}, err
 	}
 
-	removed, err := cl.Remove(idx)
-	if err != nil {
-		return types.Colleague{}, fmt.Errorf("failed to remove colleagues: %w", err)
+	return removed, nil
+}
+
+// UpdateColleague applies patch to the colleague referenced by ref, an ID or an
+// exact name, keeping its position in the list
+func (s *ColleagueService) UpdateColleague(ref string, patch types.ColleaguePatch) (types.Colleague, error) {
+	if patch.IsEmpty() {
+		return types.Colleague{}, types.ErrEmptyPatch
 	}
 
-	if err := s.manager.Save(cl); err != nil {
-		return types.Colleague{}, fmt.Errorf("colleague removed to list but failed to save: %w", err)
+	var updated types.Colleague
+	err := s.manager.Record(func(cl *types.ColleagueList) (storage.Change, error) {
+		idx, err := cl.IndexOf(ref)
+		if err != nil {
+			return storage.Change{}, fmt.Errorf("failed to find colleague: %w", suggestionError(err, *cl, ref))
+		}
+
+		previous := (*cl)[idx-1]
+		updated = patch.Apply(previous)
+		if err := updated.Validate(); err != nil {
+			return storage.Change{}, fmt.Errorf("invalid colleague data: %w", err)
+		}
+
+		if err := cl.Update(idx, updated); err != nil {
+			return storage.Change{}, fmt.Errorf("failed to update colleague: %w", err)
+		}
+		return storage.Change{Op: storage.OpEdit, Position: idx, Before: &previous, After: &updated}, nil
+	})
+	if err != nil {
+		return types.Colleague{}, err
 	}
 
-	return removed, nil
+	return updated, nil
 }
 
 func (s *ColleagueService) AllColleagues() ([]types.Colleague, error) {
@@ -65,7 +102,20 @@ func (s *ColleagueService) AllColleagues() ([]types.Colleague, error) {
 	return *cl, nil
 }
 
-func (s *ColleagueService) FindColleague(name string) ([]types.Colleague, error) {
+// Suggest returns up to three colleague names close to name, best first, to
+// offer when a search finds nobody. Commands that change colleagues only ever
+// suggest close names, so that a typo cannot pick the wrong colleague
+func (s *ColleagueService) Suggest(name string) ([]string, error) {
+	cl, err := s.manager.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load colleagues: %w", err)
+	}
+
+	return suggest(*cl, name), nil
+}
+
+// Search returns the colleagues matching q, with statuses computed at now
+func (s *ColleagueService) Search(q Query, now time.Time) ([]types.Colleague, error) {
 	cl, err := s.manager.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load colleagues: %w", err)
@@ -73,10 +123,79 @@ func (s *ColleagueService) FindColleague(name string) ([]types.Colleague, error)
 
 	var results types.ColleagueList
 	for _, c := range *cl {
-		if strings.Contains(strings.ToLower(c.Name), strings.ToLower(name)) {
+		if q.Match(c, now) {
 			results = append(results, c)
 		}
 	}
 
 	return results, nil
 }
+
+// SearchFuzzy is Search with names matched loosely, for commands that only
+// show colleagues. When the name terms of q match nobody, it returns the
+// colleagues matching the other terms whose names are close to every name
+// term, ignoring case and accents, best first, so that "Prya" finds Priya and
+// "lucio b." finds Lúcio Bianchi
+func (s *ColleagueService) SearchFuzzy(q Query, now time.Time) ([]types.Colleague, error) {
+	exact, err := s.Search(q, now)
+	if err != nil || len(exact) > 0 {
+		return exact, err
+	}
+
+	names, rest := q.splitNames()
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	candidates, err := s.Search(rest, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []match
+	for _, c := range candidates {
+		if score := termsScore(c.Name, names); score >= fuzzyThreshold {
+			matches = append(matches, match{Colleague: c, Score: score})
+		}
+	}
+	sortMatches(matches)
+
+	results := make([]types.Colleague, len(matches))
+	for i, m := range matches {
+		results[i] = m.Colleague
+	}
+	return results, nil
+}
+
+// Backups returns the available snapshots of the colleagues file, newest first
+func (s *ColleagueService) Backups() ([]storage.Backup, error) {
+	return s.manager.Backups()
+}
+
+// Restore replaces the colleagues file with the snapshot referenced by ref, a
+// snapshot name or its position in Backups
+func (s *ColleagueService) Restore(ref string) (storage.Backup, types.ColleagueList, error) {
+	backup, cl, err := s.manager.Restore(ref)
+	if err != nil {
+		return storage.Backup{}, nil, fmt.Errorf("failed to restore backup: %w", err)
+	}
+	return backup, *cl, nil
+}
+
+// Undo reverses the last add, remove or edit and returns its journal entry
+func (s *ColleagueService) Undo() (storage.JournalEntry, error) {
+	entry, err := s.manager.Undo()
+	if err != nil {
+		return storage.JournalEntry{}, fmt.Errorf("failed to undo: %w", err)
+	}
+	return entry, nil
+}
+
+// Redo reapplies the last undone change and returns its journal entry
+func (s *ColleagueService) Redo() (storage.JournalEntry, error) {
+	entry, err := s.manager.Redo()
+	if err != nil {
+		return storage.JournalEntry{}, fmt.Errorf("failed to redo: %w", err)
+	}
+	return entry, nil
+}
@@ -2,8 +2,13 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/matteo-gildone/teamtime/internals/storage"
 	"github.com/matteo-gildone/teamtime/internals/types"
@@ -112,6 +117,32 @@ func TestColleagueService_AddColleague(t *testing.T) {
 	})
 }
 
+func TestColleagueService_AddColleague_Concurrent(t *testing.T) {
+	const adders = 10
+	_, m := setUpTestService(t)
+	homeDir := filepath.Dir(filepath.Dir(m.GetFilePath()))
+
+	var wg sync.WaitGroup
+	for i := 0; i < adders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Separate managers on the same file behave like separate processes
+			other, err := storage.NewManager(homeDir)
+			if err != nil {
+				t.Errorf("failed to create manager: %v", err)
+				return
+			}
+			if _, err := NewColleagueService(other).AddColleague(fmt.Sprintf("Colleague %d", i), "London", "Europe/London"); err != nil {
+				t.Errorf("failed to add colleague: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assertColleagueCount(t, m, adders)
+}
+
 func TestColleagueService_RemoveColleague(t *testing.T) {
 	t.Run("remove first colleague", func(t *testing.T) {
 		svc, m := setUpTestService(t)
@@ -120,7 +151,7 @@ func TestColleagueService_RemoveColleague(t *testing.T) {
 			mustNewColleague(t, "Bob", "NYC", "America/New_York"),
 		})
 
-		removed, err := svc.RemoveColleague(1)
+		removed, err := svc.RemoveColleague("Alice")
 
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -133,6 +164,27 @@ func TestColleagueService_RemoveColleague(t *testing.T) {
 		assertColleagueCount(t, m, 1)
 	})
 
+	t.Run("remove by id", func(t *testing.T) {
+		svc, m := setUpTestService(t)
+		bob := mustNewColleague(t, "Bob", "NYC", "America/New_York")
+		setupInitialColleagues(t, m, []types.Colleague{
+			mustNewColleague(t, "Alice", "London", "Europe/London"),
+			bob,
+		})
+
+		removed, err := svc.RemoveColleague(bob.ID)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if removed.ID != bob.ID || removed.Name != "Bob" {
+			t.Errorf("got %+v, want %+v", removed, bob)
+		}
+
+		assertColleagueCount(t, m, 1)
+	})
+
 	t.Run("remove last colleague", func(t *testing.T) {
 		svc, m := setUpTestService(t)
 		setupInitialColleagues(t, m, []types.Colleague{
@@ -140,7 +192,7 @@ func TestColleagueService_RemoveColleague(t *testing.T) {
 			mustNewColleague(t, "Bob", "NYC", "America/New_York"),
 		})
 
-		removed, err := svc.RemoveColleague(2)
+		removed, err := svc.RemoveColleague("bob")
 
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -159,7 +211,7 @@ func TestColleagueService_RemoveColleague(t *testing.T) {
 			mustNewColleague(t, "Alice", "London", "Europe/London"),
 		})
 
-		removed, err := svc.RemoveColleague(1)
+		removed, err := svc.RemoveColleague("Alice")
 
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -176,38 +228,47 @@ func TestColleagueService_RemoveColleague(t *testing.T) {
 		tests := []struct {
 			name          string
 			initial       []types.Colleague
-			removeIndex   int
+			removeRef     string
 			wantErrorType error
 		}{
 			{
 				name:          "empty list",
 				initial:       []types.Colleague{},
-				removeIndex:   1,
+				removeRef:     "Alice",
 				wantErrorType: types.ErrEmptyList,
 			},
 			{
-				name: "index zero",
+				name: "unknown name",
 				initial: []types.Colleague{
 					mustNewColleague(t, "Alice", "London", "Europe/London"),
 				},
-				removeIndex:   0,
-				wantErrorType: types.ErrorInvalidIndex,
+				removeRef:     "Bob",
+				wantErrorType: types.ErrNotFound,
 			},
 			{
-				name: "negative index",
+				name: "partial name",
 				initial: []types.Colleague{
 					mustNewColleague(t, "Alice", "London", "Europe/London"),
 				},
-				removeIndex:   -1,
-				wantErrorType: types.ErrorInvalidIndex,
+				removeRef:     "Ali",
+				wantErrorType: types.ErrNotFound,
 			},
 			{
-				name: "index too large",
+				name: "list position is not an id",
 				initial: []types.Colleague{
 					mustNewColleague(t, "Alice", "London", "Europe/London"),
 				},
-				removeIndex:   5,
-				wantErrorType: types.ErrorInvalidIndex,
+				removeRef:     "1",
+				wantErrorType: types.ErrNotFound,
+			},
+			{
+				name: "ambiguous name",
+				initial: []types.Colleague{
+					mustNewColleague(t, "Alice", "London", "Europe/London"),
+					mustNewColleague(t, "alice", "Leeds", "Europe/London"),
+				},
+				removeRef:     "Alice",
+				wantErrorType: types.ErrAmbiguousName,
 			},
 		}
 
@@ -215,7 +276,7 @@ func TestColleagueService_RemoveColleague(t *testing.T) {
 			t.Run(tt.name, func(t *testing.T) {
 				svc, m := setUpTestService(t)
 				setupInitialColleagues(t, m, tt.initial)
-				_, err := svc.RemoveColleague(tt.removeIndex)
+				_, err := svc.RemoveColleague(tt.removeRef)
 
 				if err == nil {
 					t.Fatal("expected error got nil")
@@ -229,6 +290,96 @@ func TestColleagueService_RemoveColleague(t *testing.T) {
 	})
 }
 
+func TestColleagueService_UpdateColleague(t *testing.T) {
+	t.Run("update city by name keeps position", func(t *testing.T) {
+		svc, m := setUpTestService(t)
+		setupInitialColleagues(t, m, []types.Colleague{
+			mustNewColleague(t, "Alice", "London", "Europe/London"),
+			mustNewColleague(t, "Bob", "NYC", "America/New_York"),
+			mustNewColleague(t, "Matteo", "Tokyo", "Asia/Tokyo"),
+		})
+
+		city := "Boston"
+		updated, err := svc.UpdateColleague("bob", types.ColleaguePatch{City: &city})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if updated.City != "Boston" || updated.Timezone != "America/New_York" {
+			t.Errorf("got %+v, want city Boston and unchanged timezone", updated)
+		}
+
+		all, _ := svc.AllColleagues()
+		if updated.ID != all[1].ID {
+			t.Errorf("expected id %q to be kept, got %q", all[1].ID, updated.ID)
+		}
+		if all[1].Name != "Bob" || all[1].City != "Boston" {
+			t.Errorf("got %+v at position 2, want updated Bob", all[1])
+		}
+		assertColleagueCount(t, m, 3)
+	})
+
+	t.Run("update timezone by id", func(t *testing.T) {
+		svc, m := setUpTestService(t)
+		setupInitialColleagues(t, m, []types.Colleague{
+			mustNewColleague(t, "Alice", "London", "Europe/London"),
+		})
+
+		all, _ := svc.AllColleagues()
+		tz := "  Europe/Lisbon "
+		city := "Lisbon"
+		updated, err := svc.UpdateColleague(all[0].ID, types.ColleaguePatch{City: &city, Timezone: &tz})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if updated.Timezone != "Europe/Lisbon" {
+			t.Errorf("got %q, want %q", updated.Timezone, "Europe/Lisbon")
+		}
+	})
+
+	t.Run("error cases", func(t *testing.T) {
+		empty := ""
+		invalidTZ := "Europe/Atlantis"
+		name := "Alicia"
+		tests := []struct {
+			name    string
+			ref     string
+			patch   types.ColleaguePatch
+			wantErr error
+		}{
+			{name: "empty patch", ref: "Alice", wantErr: types.ErrEmptyPatch},
+			{name: "unknown name", ref: "Zoe", patch: types.ColleaguePatch{Name: &name}, wantErr: types.ErrNotFound},
+			{name: "unknown id", ref: "ffffff", patch: types.ColleaguePatch{Name: &name}, wantErr: types.ErrNotFound},
+			{name: "empty name", ref: "Alice", patch: types.ColleaguePatch{Name: &empty}, wantErr: types.ErrMissingName},
+			{name: "invalid timezone", ref: "Alice", patch: types.ColleaguePatch{Timezone: &invalidTZ}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				svc, m := setUpTestService(t)
+				setupInitialColleagues(t, m, []types.Colleague{
+					mustNewColleague(t, "Alice", "London", "Europe/London"),
+				})
+
+				_, err := svc.UpdateColleague(tt.ref, tt.patch)
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("got %v, want %v", err, tt.wantErr)
+				}
+
+				all, _ := svc.AllColleagues()
+				if all[0].Name != "Alice" || all[0].Timezone != "Europe/London" {
+					t.Errorf("expected colleague to be unchanged, got %+v", all[0])
+				}
+			})
+		}
+	})
+}
+
 func TestColleagueService_AllColleagues(t *testing.T) {
 	t.Run("empty list", func(t *testing.T) {
 		svc, _ := setUpTestService(t)
@@ -293,87 +444,139 @@ func TestColleagueService_AllColleagues(t *testing.T) {
 	})
 }
 
-func TestColleagueService_FindColleague(t *testing.T) {
-	t.Run("single match", func(t *testing.T) {
-		svc, m := setUpTestService(t)
-		setupInitialColleagues(t, m, []types.Colleague{
-			mustNewColleague(t, "Alice", "London", "Europe/London"),
-			mustNewColleague(t, "Bob", "NYC", "America/New_York"),
-		})
-
-		results, err := svc.FindColleague("Alice")
-
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
-
-		if len(results) != 1 {
-			t.Errorf("got: %d, want 1", len(results))
-		}
-
-		if results[0].Name != "Alice" {
-			t.Errorf("got: %q, want %q", results[0].Name, "Alice")
-		}
+func TestColleagueService_Suggest(t *testing.T) {
+	svc, m := setUpTestService(t)
+	setupInitialColleagues(t, m, []types.Colleague{
+		mustNewColleague(t, "Bob", "NYC", "America/New_York"),
+		mustNewColleague(t, "Pia", "São Paulo", "America/Sao_Paulo"),
+		mustNewColleague(t, "Priya", "Pune", "Asia/Kolkata"),
+		mustNewColleague(t, "Lúcio Bianchi", "Poggibonsi", "Europe/Rome"),
 	})
 
-	t.Run("case insensitive", func(t *testing.T) {
-		svc, m := setUpTestService(t)
-		setupInitialColleagues(t, m, []types.Colleague{
-			mustNewColleague(t, "Alice", "London", "Europe/London"),
-		})
+	names, err := svc.Suggest("Prya")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-		results, err := svc.FindColleague("alice")
+	if want := []string{"Priya", "Pia"}; !slices.Equal(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
 
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
+	names, err = svc.Suggest("lucio b.")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(names) == 0 || names[0] != "Lúcio Bianchi" {
+		t.Errorf("got %v, want Lúcio Bianchi first", names)
+	}
 
-		if len(results) != 1 {
-			t.Errorf("got: %d, want 1", len(results))
-		}
+	_, err = svc.RemoveColleague("Pria")
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", types.ErrNotFound, err)
+	}
+	if !strings.Contains(err.Error(), `did you mean "Priya" or "Pia"?`) {
+		t.Errorf("expected suggestions in %q", err)
+	}
+}
 
-		if results[0].Name != "Alice" {
-			t.Errorf("got: %q, want %q", results[0].Name, "Alice")
-		}
+func TestColleagueService_Search(t *testing.T) {
+	svc, m := setUpTestService(t)
+	setupInitialColleagues(t, m, []types.Colleague{
+		mustNewColleague(t, "Alice", "London", "Europe/London", types.WithTags(types.Tags{"backend", "oncall"})),
+		mustNewColleague(t, "Alina", "Rome", "Europe/Rome", types.WithTags(types.Tags{"frontend"})),
+		mustNewColleague(t, "Bob", "NYC", "America/New_York", types.WithTags(types.Tags{"backend"})),
+		mustNewColleague(t, "All", "Oslo", "Europe/Oslo"),
 	})
 
-	t.Run("no matches", func(t *testing.T) {
-		svc, m := setUpTestService(t)
-		setupInitialColleagues(t, m, []types.Colleague{
-			mustNewColleague(t, "Alice", "London", "Europe/London"),
-			mustNewColleague(t, "Bob", "NYC", "America/New_York"),
-		})
+	tests := []struct {
+		name   string
+		query  []string
+		tags   types.Tags
+		anyTag bool
+		want   string
+	}{
+		{name: "empty query", want: "Alice,Alina,Bob,All"},
+		{name: "all is a name", query: []string{"all"}, want: "All"},
+		{name: "single tag flag", tags: types.Tags{"backend"}, want: "Alice,Bob"},
+		{name: "every tag flag", tags: types.Tags{"backend", "oncall"}, want: "Alice"},
+		{name: "any tag flag", tags: types.Tags{"oncall", "frontend"}, anyTag: true, want: "Alice,Alina"},
+		{name: "name and tag", query: []string{"ali"}, tags: types.Tags{"frontend"}, want: "Alina"},
+		{name: "timezone pattern", query: []string{"tz:Europe/*", "-city:rome"}, want: "Alice,All"},
+		{name: "unknown tag", query: []string{"tag:design"}, want: ""},
+	}
 
-		colleagues, _ := svc.FindColleague("Matteo")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.query...)
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
 
-		if len(colleagues) != 0 {
-			t.Fatalf("expected empty list got: %d", len(colleagues))
-		}
-	})
+			results, err := svc.Search(q.WithTags(tt.tags, tt.anyTag), time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 
-	t.Run("multiple matches", func(t *testing.T) {
-		svc, m := setUpTestService(t)
-		setupInitialColleagues(t, m, []types.Colleague{
-			mustNewColleague(t, "Alice", "London", "Europe/London"),
-			mustNewColleague(t, "Alia", "NYC", "America/New_York"),
+			var names []string
+			for _, c := range results {
+				names = append(names, c.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("got: %q, want %q", got, tt.want)
+			}
 		})
+	}
+}
+
+func TestColleagueService_SearchFuzzy(t *testing.T) {
+	svc, m := setUpTestService(t)
+	setupInitialColleagues(t, m, []types.Colleague{
+		mustNewColleague(t, "Pia", "São Paulo", "America/Sao_Paulo"),
+		mustNewColleague(t, "Lúcio Bianchi", "Poggibonsi", "Europe/Rome", types.WithTags(types.Tags{"frontend"})),
+		mustNewColleague(t, "Priya", "Pune", "Asia/Kolkata", types.WithTags(types.Tags{"backend"})),
+		mustNewColleague(t, "Anna", "Oslo", "Europe/Oslo"),
+		mustNewColleague(t, "Ana", "Lisbon", "Europe/Lisbon"),
+		mustNewColleague(t, "Marta", "Madrid", "Europe/Madrid"),
+		mustNewColleague(t, "Martina", "Milan", "Europe/Rome"),
+	})
 
-		results, err := svc.FindColleague("Ali")
+	tests := []struct {
+		name  string
+		query []string
+		want  string
+	}{
+		{name: "typo", query: []string{"Prya"}, want: "Priya"},
+		{name: "initial", query: []string{"lucio b."}, want: "Lúcio Bianchi"},
+		{name: "accents are ignored", query: []string{"lucio"}, want: "Lúcio Bianchi"},
+		{name: "exact matches hide close ones", query: []string{"Anna"}, want: "Anna"},
+		{name: "best first", query: []string{"Martna"}, want: "Martina,Marta"},
+		{name: "other terms still apply", query: []string{"prya", "tag:frontend"}, want: ""},
+		{name: "every name term", query: []string{"prya", "lucio"}, want: ""},
+		{name: "too far", query: []string{"Pryia"}, want: ""},
+		{name: "no name terms", query: []string{"city:berlin"}, want: ""},
+	}
 
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.query...)
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
 
-		if len(results) != 2 {
-			t.Errorf("got: %d, want 0", len(results))
-		}
+			results, err := svc.SearchFuzzy(q, time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 
-		for _, result := range results {
-			if !strings.Contains(result.Name, "Ali") {
-				t.Errorf("got: %q, want %q", result.Name, "Ali")
+			var names []string
+			for _, c := range results {
+				names = append(names, c.Name)
 			}
-		}
-	})
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("got: %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestColleagueService_Integration(t *testing.T) {
@@ -383,12 +586,13 @@ func TestColleagueService_Integration(t *testing.T) {
 	svc.AddColleague("Bob", "NYC", "America/New_York")
 	assertColleagueCount(t, m, 2)
 
-	results, _ := svc.FindColleague("Alice")
+	q, _ := ParseQuery("Alice")
+	results, _ := svc.Search(q, time.Now())
 	if len(results) != 1 {
 		t.Fatalf("got: %d want 1", len(results))
 	}
 
-	svc.RemoveColleague(1)
+	svc.RemoveColleague("Alice")
 	assertColleagueCount(t, m, 1)
 
 	all, _ := svc.AllColleagues()
@@ -398,6 +602,39 @@ func TestColleagueService_Integration(t *testing.T) {
 	}
 }
 
+func TestColleagueService_Restore(t *testing.T) {
+	svc, m := setUpTestService(t)
+
+	if _, err := svc.AddColleague("Alice", "London", "Europe/London"); err != nil {
+		t.Fatalf("failed to add colleague: %v", err)
+	}
+	if _, err := svc.RemoveColleague("Alice"); err != nil {
+		t.Fatalf("failed to remove colleague: %v", err)
+	}
+	assertColleagueCount(t, m, 0)
+
+	backups, err := svc.Backups()
+	if err != nil {
+		t.Fatalf("failed to list backups: %v", err)
+	}
+	if len(backups) == 0 {
+		t.Fatal("expected at least one backup")
+	}
+
+	_, restored, err := svc.Restore("1")
+	if err != nil {
+		t.Fatalf("failed to restore backup: %v", err)
+	}
+	if len(restored) != 1 || restored[0].Name != "Alice" {
+		t.Errorf("got: %v, want Alice", restored)
+	}
+	assertColleagueCount(t, m, 1)
+
+	if _, _, err := svc.Restore("missing.json"); !errors.Is(err, storage.ErrBackupNotFound) {
+		t.Errorf("got: %v, want %v", err, storage.ErrBackupNotFound)
+	}
+}
+
 func setUpTestService(t *testing.T) (*ColleagueService, *storage.Manager) {
 	t.Helper()
 	tempDir := t.TempDir()
@@ -442,9 +679,9 @@ func assertColleagueCount(t *testing.T, m *storage.Manager, want int) {
 	}
 }
 
-func mustNewColleague(t *testing.T, name, city, tz string) types.Colleague {
+func mustNewColleague(t *testing.T, name, city, tz string, opts ...types.Option) types.Colleague {
 	t.Helper()
-	colleague, err := types.NewColleague(name, city, tz)
+	colleague, err := types.NewColleague(name, city, tz, opts...)
 	if err != nil {
 		t.Fatalf("failed to create test colleague: %v", err)
 	}
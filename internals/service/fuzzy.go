@@ -0,0 +1,187 @@
+package service
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/matteo-gildone/teamtime/internals/fold"
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+const (
+	// fuzzyThreshold is the lowest score SearchFuzzy accepts as a match
+	fuzzyThreshold = 0.75
+	// suggestThreshold is the lowest score offered as a suggestion
+	suggestThreshold = 0.5
+	// maxSuggestions is how many names a "did you mean" message offers
+	maxSuggestions = 3
+)
+
+// match is a colleague found by a fuzzy search with its score, from 0 for
+// nothing in common to 1 for a name containing the query
+type match struct {
+	Colleague types.Colleague
+	Score     float64
+}
+
+// rankNames scores every colleague's name against query and returns those
+// scoring at least threshold, best first. Equal scores keep the list order
+func rankNames(colleagues []types.Colleague, query string, threshold float64) []match {
+	var matches []match
+	for _, c := range colleagues {
+		if score := nameScore(c.Name, query); score >= threshold {
+			matches = append(matches, match{Colleague: c, Score: score})
+		}
+	}
+	sortMatches(matches)
+	return matches
+}
+
+// sortMatches sorts matches best first, keeping the list order of equal scores
+func sortMatches(matches []match) {
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+}
+
+// termsScore is the score of the name against the loosest matching value of
+// each term, and so of the term it matches worst
+func termsScore(name string, terms [][]string) float64 {
+	score := 1.0
+	for _, values := range terms {
+		var best float64
+		for _, value := range values {
+			best = max(best, nameScore(name, value))
+		}
+		score = min(score, best)
+	}
+	return score
+}
+
+// nameScore compares a name with a query after folding case and accents. A
+// name containing the query scores 1; otherwise each query word is matched
+// with the closest word of the name, where a word that starts the name word
+// like an initial ("b." for "Bianchi") scores 0.9 and other words score by
+// edit distance. The result is the better of the average word score and the
+// edit distance score of the whole strings
+func nameScore(name, query string) float64 {
+	name, query = fold.Text(name), fold.Text(query)
+	if query == "" {
+		return 0
+	}
+	if strings.Contains(name, query) {
+		return 1
+	}
+
+	nameWords, queryWords := words(name), words(query)
+	if len(nameWords) == 0 || len(queryWords) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, q := range queryWords {
+		var best float64
+		for _, n := range nameWords {
+			best = max(best, wordScore(n, q))
+		}
+		total += best
+	}
+
+	return max(total/float64(len(queryWords)), similarity(name, query))
+}
+
+func wordScore(nameWord, queryWord string) float64 {
+	switch {
+	case nameWord == queryWord:
+		return 1
+	case strings.HasPrefix(nameWord, queryWord):
+		return 0.9
+	default:
+		return similarity(nameWord, queryWord)
+	}
+}
+
+// similarity turns the edit distance between a and b into a score from 0 to 1
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the number of single rune insertions, deletions and
+// substitutions needed to turn a into b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// words splits s into words made of letters and digits
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// suggestionError adds the closest names to a colleague not found error
+func suggestionError(err error, colleagues []types.Colleague, ref string) error {
+	if !errors.Is(err, types.ErrNotFound) {
+		return err
+	}
+
+	names := suggest(colleagues, ref)
+	if len(names) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w, did you mean %s?", err, DidYouMean(names))
+}
+
+// suggest returns the names closest to query, best first
+func suggest(colleagues []types.Colleague, query string) []string {
+	var names []string
+	for _, m := range rankNames(colleagues, query, suggestThreshold) {
+		if slices.Contains(names, m.Colleague.Name) {
+			continue
+		}
+		names = append(names, m.Colleague.Name)
+		if len(names) == maxSuggestions {
+			break
+		}
+	}
+	return names
+}
+
+// DidYouMean joins names for a suggestion, e.g. `"Priya" or "Pia"`
+func DidYouMean(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
@@ -0,0 +1,46 @@
+package service
+
+import "testing"
+
+func TestNameScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		min   float64
+		max   float64
+	}{
+		{name: "Priya", query: "pri", min: 1, max: 1},
+		{name: "Lúcio Bianchi", query: "LUCIO", min: 1, max: 1},
+		{name: "Priya", query: "Prya", min: 0.8, max: 0.8},
+		{name: "Lúcio Bianchi", query: "lucio b.", min: 0.95, max: 0.95},
+		{name: "Lúcio Bianchi", query: "bianki", min: 0.7, max: 0.72},
+		{name: "Bob", query: "Priya", min: 0, max: 0.2},
+		{name: "Bob", query: "", min: 0, max: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.query, func(t *testing.T) {
+			got := nameScore(tt.name, tt.query)
+			if got < tt.min-1e-9 || got > tt.max+1e-9 {
+				t.Errorf("got %.3f, want between %.2f and %.2f", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{names: []string{"Priya"}, want: `"Priya"`},
+		{names: []string{"Priya", "Pia"}, want: `"Priya" or "Pia"`},
+		{names: []string{"Priya", "Pia", "Pim"}, want: `"Priya", "Pia" or "Pim"`},
+	}
+
+	for _, tt := range tests {
+		if got := DidYouMean(tt.names); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
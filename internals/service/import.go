@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/matteo-gildone/teamtime/internals/roster"
+	"github.com/matteo-gildone/teamtime/internals/storage"
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+var (
+	ErrAlreadyExists = errors.New("colleague already exists")
+	ErrIDConflict    = errors.New("id different from the existing colleague's")
+)
+
+// ImportMode decides what happens to colleagues already in the list
+type ImportMode int
+
+const (
+	// ImportAdd adds every colleague and fails if any name is already taken
+	ImportAdd ImportMode = iota
+	// ImportSkip adds new colleagues and leaves existing ones with the same
+	// name untouched
+	ImportSkip
+	// ImportUpdate adds new colleagues and overwrites the details of existing
+	// ones with the same name, keeping their IDs
+	ImportUpdate
+	// ImportReplace replaces the whole list with the imported colleagues
+	ImportReplace
+)
+
+// ImportResult counts what an import changed
+type ImportResult struct {
+	Added   int
+	Updated int
+	Skipped int
+	Removed int
+}
+
+// ImportColleagues adds the colleagues of entries, already validated, in a
+// single write, journaled as one change so that undo reverts the whole import.
+// Names are compared ignoring case. Nothing is written if any name is already
+// taken in ImportAdd mode, or matches several colleagues in ImportUpdate mode,
+// or if an ID given in the file is used by another colleague, or differs from
+// the one of the colleague it updates, which is reported as a
+// *roster.LineError
+func (s *ColleagueService) ImportColleagues(entries []roster.Entry, mode ImportMode) (ImportResult, error) {
+	var result ImportResult
+	err := s.manager.Record(func(cl *types.ColleagueList) (storage.Change, error) {
+		before := slices.Clone(*cl)
+		if mode == ImportReplace {
+			result.Removed = len(*cl)
+			*cl = types.ColleagueList{}
+		}
+
+		var taken []string
+		var collisions []error
+		for _, e := range entries {
+			c := e.Colleague
+			idx, err := indexOfName(*cl, c.Name)
+			if err != nil {
+				return storage.Change{}, err
+			}
+
+			switch {
+			case idx == 0 && e.IDGiven && cl.HasID(c.ID):
+				collisions = append(collisions, &roster.LineError{Line: e.Line, Err: fmt.Errorf("%w: %q", types.ErrDuplicateID, c.ID)})
+			case idx == 0:
+				if err := cl.Add(c); err != nil {
+					return storage.Change{}, err
+				}
+				result.Added++
+			case mode == ImportSkip:
+				result.Skipped++
+			case mode == ImportUpdate && e.IDGiven && !strings.EqualFold(c.ID, (*cl)[idx-1].ID):
+				collisions = append(collisions, &roster.LineError{Line: e.Line, Err: fmt.Errorf("%w: %q given for %s, whose id is %q", ErrIDConflict, c.ID, (*cl)[idx-1].Name, (*cl)[idx-1].ID)})
+			case mode == ImportUpdate:
+				c.ID = (*cl)[idx-1].ID
+				if err := cl.Update(idx, c); err != nil {
+					return storage.Change{}, fmt.Errorf("failed to update colleague: %w", err)
+				}
+				result.Updated++
+			default:
+				taken = append(taken, fmt.Sprintf("%q", c.Name))
+			}
+		}
+
+		if len(collisions) > 0 {
+			return storage.Change{}, errors.Join(collisions...)
+		}
+		if len(taken) > 0 {
+			return storage.Change{}, fmt.Errorf("%w: %s", ErrAlreadyExists, strings.Join(taken, ", "))
+		}
+		return storage.Change{Op: storage.OpImport, BeforeList: before, AfterList: slices.Clone(*cl)}, nil
+	})
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	return result, nil
+}
+
+// indexOfName returns the 1-based position of the colleague called name, or
+// 0 if there is none
+func indexOfName(cl types.ColleagueList, name string) (int, error) {
+	found := 0
+	for i, c := range cl {
+		if !strings.EqualFold(c.Name, name) {
+			continue
+		}
+		if found != 0 {
+			return 0, fmt.Errorf("%w: %q", types.ErrAmbiguousName, name)
+		}
+		found = i + 1
+	}
+	return found, nil
+}
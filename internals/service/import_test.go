@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/matteo-gildone/teamtime/internals/roster"
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func TestColleagueService_ImportColleagues(t *testing.T) {
+	existing := func(t *testing.T) []types.Colleague {
+		t.Helper()
+		return []types.Colleague{
+			mustNewColleague(t, "Alice", "London", "Europe/London"),
+			mustNewColleague(t, "Priya", "Pune", "Asia/Kolkata", types.WithTags(types.Tags{"backend"})),
+		}
+	}
+	imported := func(t *testing.T) []roster.Entry {
+		t.Helper()
+		return []roster.Entry{
+			{Line: 2, Colleague: mustNewColleague(t, "priya", "Mumbai", "Asia/Kolkata")},
+			{Line: 3, Colleague: mustNewColleague(t, "Kenji", "Tokyo", "Asia/Tokyo")},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		mode       ImportMode
+		want       ImportResult
+		wantErr    error
+		wantCities []string
+	}{
+		{name: "add refuses taken names", mode: ImportAdd, wantErr: ErrAlreadyExists, wantCities: []string{"London", "Pune"}},
+		{name: "skip", mode: ImportSkip, want: ImportResult{Added: 1, Skipped: 1}, wantCities: []string{"London", "Pune", "Tokyo"}},
+		{name: "update", mode: ImportUpdate, want: ImportResult{Added: 1, Updated: 1}, wantCities: []string{"London", "Mumbai", "Tokyo"}},
+		{name: "replace", mode: ImportReplace, want: ImportResult{Added: 2, Removed: 2}, wantCities: []string{"Mumbai", "Tokyo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setUpTestService(t)
+			before := existing(t)
+			setupInitialColleagues(t, m, before)
+
+			got, err := svc.ImportColleagues(imported(t), tt.mode)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			all, err := svc.AllColleagues()
+			if err != nil {
+				t.Fatalf("failed to load colleagues: %v", err)
+			}
+			if len(all) != len(tt.wantCities) {
+				t.Fatalf("got %d colleagues, want %d", len(all), len(tt.wantCities))
+			}
+			for i, c := range all {
+				if c.City != tt.wantCities[i] {
+					t.Errorf("colleague %d: got city %q, want %q", i, c.City, tt.wantCities[i])
+				}
+			}
+
+			if tt.mode == ImportUpdate && all[1].ID != before[1].ID {
+				t.Errorf("update changed the ID from %q to %q", before[1].ID, all[1].ID)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			// The whole import is a single change of the journal
+			if _, err := svc.Undo(); err != nil {
+				t.Fatalf("undo failed: %v", err)
+			}
+			assertCities(t, svc, []string{"London", "Pune"})
+
+			if _, err := svc.Redo(); err != nil {
+				t.Fatalf("redo failed: %v", err)
+			}
+			assertCities(t, svc, tt.wantCities)
+		})
+	}
+
+	t.Run("given ids must be free", func(t *testing.T) {
+		svc, m := setUpTestService(t)
+		before := existing(t)
+		setupInitialColleagues(t, m, before)
+
+		entries := imported(t)
+		entries[1].Colleague.ID = before[0].ID
+		entries[1].IDGiven = true
+
+		_, err := svc.ImportColleagues(entries, ImportSkip)
+		if !errors.Is(err, types.ErrDuplicateID) {
+			t.Fatalf("expected %v, got %v", types.ErrDuplicateID, err)
+		}
+		var lineErr *roster.LineError
+		if !errors.As(err, &lineErr) || lineErr.Line != 3 {
+			t.Errorf("expected the error on line 3, got %v", err)
+		}
+		assertCities(t, svc, []string{"London", "Pune"})
+
+		// A generated id that happens to be taken is replaced
+		entries[1].IDGiven = false
+		if _, err := svc.ImportColleagues(entries, ImportSkip); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		all, _ := svc.AllColleagues()
+		if all[2].ID == before[0].ID {
+			t.Errorf("expected a new id, got %q", all[2].ID)
+		}
+	})
+
+	t.Run("updates keep the ids given", func(t *testing.T) {
+		svc, m := setUpTestService(t)
+		before := existing(t)
+		setupInitialColleagues(t, m, before)
+
+		entries := imported(t)
+		entries[0].Colleague.ID = before[0].ID
+		entries[0].IDGiven = true
+
+		_, err := svc.ImportColleagues(entries, ImportUpdate)
+		if !errors.Is(err, ErrIDConflict) {
+			t.Fatalf("expected %v, got %v", ErrIDConflict, err)
+		}
+		var lineErr *roster.LineError
+		if !errors.As(err, &lineErr) || lineErr.Line != 2 {
+			t.Errorf("expected the error on line 2, got %v", err)
+		}
+		assertCities(t, svc, []string{"London", "Pune"})
+
+		// The id of the colleague being updated matches
+		entries[0].Colleague.ID = strings.ToUpper(before[1].ID)
+		if _, err := svc.ImportColleagues(entries, ImportUpdate); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertCities(t, svc, []string{"London", "Mumbai", "Tokyo"})
+	})
+}
+
+func assertCities(t *testing.T, svc *ColleagueService, want []string) {
+	t.Helper()
+	all, err := svc.AllColleagues()
+	if err != nil {
+		t.Fatalf("failed to load colleagues: %v", err)
+	}
+
+	var got []string
+	for _, c := range all {
+		got = append(got, c.City)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got cities %v, want %v", got, want)
+	}
+}
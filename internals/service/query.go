@@ -0,0 +1,343 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/fold"
+	"github.com/matteo-gildone/teamtime/internals/schedule"
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Query fields. A term without a field matches names
+const (
+	FieldName     = "name"
+	FieldCity     = "city"
+	FieldTimezone = "tz"
+	FieldTag      = "tag"
+	FieldID       = "id"
+	FieldStatus   = "status"
+)
+
+var fieldAliases = map[string]string{
+	FieldName:     FieldName,
+	FieldCity:     FieldCity,
+	FieldTimezone: FieldTimezone,
+	"timezone":    FieldTimezone,
+	FieldTag:      FieldTag,
+	"tags":        FieldTag,
+	FieldID:       FieldID,
+	FieldStatus:   FieldStatus,
+}
+
+// Query selects colleagues with terms such as "city:berlin tz:Asia/*
+// tag:oncall status:work". Every term must match; the comma separated values
+// of a term are alternatives. A term prefixed with '-' or '!' must not match.
+// Values match ignoring case and accents: names, cities and timezones contain
+// the value, tags and IDs equal it, and '*' and '?' make any value a wildcard
+// pattern for the whole field. The zero Query matches everyone
+type Query struct {
+	terms []term
+}
+
+type term struct {
+	field  string
+	values []string
+	negate bool
+}
+
+// ParseQuery parses the terms found in args. Each argument may hold several
+// terms separated by spaces; double quotes keep a value with spaces together,
+// e.g. city:"new york"
+func ParseQuery(args ...string) (Query, error) {
+	var q Query
+	for _, arg := range args {
+		tokens, err := tokenize(arg)
+		if err != nil {
+			return Query{}, err
+		}
+		for _, token := range tokens {
+			t, err := parseTerm(token)
+			if err != nil {
+				return Query{}, err
+			}
+			q.terms = append(q.terms, t)
+		}
+	}
+	return q, nil
+}
+
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	var sb strings.Builder
+	inQuotes, inToken := false, false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			inToken = true
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if inToken {
+				tokens = append(tokens, sb.String())
+				sb.Reset()
+				inToken = false
+			}
+		default:
+			sb.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("%w: unterminated quote in %q", ErrInvalidQuery, s)
+	}
+	if inToken {
+		tokens = append(tokens, sb.String())
+	}
+	return tokens, nil
+}
+
+func parseTerm(token string) (term, error) {
+	var t term
+	if rest, ok := strings.CutPrefix(token, "-"); ok {
+		t.negate, token = true, rest
+	} else if rest, ok := strings.CutPrefix(token, "!"); ok {
+		t.negate, token = true, rest
+	}
+
+	t.field = FieldName
+	if field, value, ok := strings.Cut(token, ":"); ok {
+		canonical, known := fieldAliases[strings.ToLower(field)]
+		if !known {
+			return term{}, fmt.Errorf("%w: unknown field %q (use name, city, tz, tag, id or status)", ErrInvalidQuery, field)
+		}
+		t.field, token = canonical, value
+	}
+
+	for _, value := range strings.Split(token, ",") {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		if t.field == FieldStatus {
+			status, err := parseStatus(value)
+			if err != nil {
+				return term{}, err
+			}
+			value = string(status)
+		}
+		t.values = append(t.values, value)
+	}
+
+	if len(t.values) == 0 {
+		return term{}, fmt.Errorf("%w: %s needs a value", ErrInvalidQuery, t.field)
+	}
+	return t, nil
+}
+
+func parseStatus(value string) (schedule.Status, error) {
+	compact := strings.NewReplacer("-", "", "_", "", " ", "").Replace(value)
+	for _, status := range []schedule.Status{schedule.StatusWork, schedule.StatusExtended, schedule.StatusOff, schedule.StatusDayOff} {
+		if compact == strings.ReplaceAll(string(status), " ", "") {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown status %q (use work, extended, off or dayoff)", ErrInvalidQuery, value)
+}
+
+// IsEmpty reports whether the query has no terms and so matches everyone
+func (q Query) IsEmpty() bool {
+	return len(q.terms) == 0
+}
+
+// WithTags returns the query with an extra term requiring every tag, or at
+// least one of them when matchAny is set
+func (q Query) WithTags(tags types.Tags, matchAny bool) Query {
+	if len(tags) == 0 {
+		return q
+	}
+
+	terms := append([]term(nil), q.terms...)
+	if matchAny {
+		terms = append(terms, term{field: FieldTag, values: tags})
+	} else {
+		for _, tag := range tags {
+			terms = append(terms, term{field: FieldTag, values: []string{tag}})
+		}
+	}
+	return Query{terms: terms}
+}
+
+// WithStatuses returns the query with an extra term requiring one of the
+// statuses, given separately or comma separated as in a status: term. No
+// statuses leave the query unchanged
+func (q Query) WithStatuses(statuses ...string) (Query, error) {
+	var values []string
+	for _, value := range strings.Split(strings.Join(statuses, ","), ",") {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		status, err := parseStatus(value)
+		if err != nil {
+			return Query{}, err
+		}
+		values = append(values, string(status))
+	}
+
+	if len(values) == 0 {
+		return q, nil
+	}
+
+	terms := append([]term(nil), q.terms...)
+	terms = append(terms, term{field: FieldStatus, values: values})
+	return Query{terms: terms}, nil
+}
+
+// Names returns the values of the name terms that must match, without
+// wildcard patterns, e.g. to suggest close names when nothing matched
+func (q Query) Names() []string {
+	var names []string
+	for _, t := range q.terms {
+		if t.field != FieldName || t.negate {
+			continue
+		}
+		for _, value := range t.values {
+			if !isPattern(value) {
+				names = append(names, value)
+			}
+		}
+	}
+	return names
+}
+
+// splitNames returns the values of each name term that must match, without
+// wildcard patterns, and the query made of the other terms
+func (q Query) splitNames() ([][]string, Query) {
+	var names [][]string
+	var rest Query
+	for _, t := range q.terms {
+		if t.field == FieldName && !t.negate && !slices.ContainsFunc(t.values, isPattern) {
+			names = append(names, t.values)
+			continue
+		}
+		rest.terms = append(rest.terms, t)
+	}
+	return names, rest
+}
+
+// Match reports whether c matches every term, computing statuses at now
+func (q Query) Match(c types.Colleague, now time.Time) bool {
+	for _, t := range q.terms {
+		if t.match(c, now) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t term) match(c types.Colleague, now time.Time) bool {
+	for _, value := range t.values {
+		if t.matchValue(c, value, now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t term) matchValue(c types.Colleague, value string, now time.Time) bool {
+	switch t.field {
+	case FieldCity:
+		return containsText(c.City, value)
+	case FieldTimezone:
+		return containsText(c.Timezone, value)
+	case FieldID:
+		return equalText(c.ID, value)
+	case FieldTag:
+		for _, tag := range c.Tags {
+			if equalText(tag, value) {
+				return true
+			}
+		}
+		return false
+	case FieldStatus:
+		_, status, err := schedule.At(now, c)
+		return err == nil && string(status) == value
+	default:
+		return containsText(c.Name, value)
+	}
+}
+
+// String returns the query in its canonical form
+func (q Query) String() string {
+	parts := make([]string, len(q.terms))
+	for i, t := range q.terms {
+		values := make([]string, len(t.values))
+		for j, v := range t.values {
+			if strings.ContainsAny(v, " \t") {
+				v = strconv.Quote(v)
+			}
+			values[j] = v
+		}
+
+		var prefix string
+		if t.negate {
+			prefix = "-"
+		}
+		parts[i] = prefix + t.field + ":" + strings.Join(values, ",")
+	}
+	return strings.Join(parts, " ")
+}
+
+// containsText reports whether s contains value, or matches it as a whole
+// when value is a wildcard pattern, ignoring case and accents
+func containsText(s, value string) bool {
+	s, value = fold.Text(s), fold.Text(value)
+	if isPattern(value) {
+		return matchPattern(value, s)
+	}
+	return strings.Contains(s, value)
+}
+
+// equalText reports whether s equals value, or matches it when value is a
+// wildcard pattern, ignoring case and accents
+func equalText(s, value string) bool {
+	s, value = fold.Text(s), fold.Text(value)
+	if isPattern(value) {
+		return matchPattern(value, s)
+	}
+	return s == value
+}
+
+func isPattern(value string) bool {
+	return strings.ContainsAny(value, "*?")
+}
+
+// matchPattern matches s against a pattern where '*' stands for any text,
+// including '/', and '?' for a single character
+func matchPattern(pattern, s string) bool {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	return err == nil && re.MatchString(strings.ToLower(s))
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr error
+	}{
+		{name: "empty", args: nil, want: ""},
+		{name: "bare name", args: []string{"Alice"}, want: "name:alice"},
+		{name: "several terms in one argument", args: []string{"city:berlin tag:oncall"}, want: "city:berlin tag:oncall"},
+		{name: "field aliases", args: []string{"timezone:Asia/*", "tags:backend"}, want: "tz:asia/* tag:backend"},
+		{name: "negation", args: []string{"-city:rome", "!tag:oncall"}, want: "-city:rome -tag:oncall"},
+		{name: "alternatives", args: []string{"status:work,extended"}, want: "status:work,extended"},
+		{name: "day off spellings", args: []string{"status:dayoff,day-off,day_off"}, want: `status:"day off","day off","day off"`},
+		{name: "quoted value", args: []string{`city:"New York"`}, want: `city:"new york"`},
+		{name: "unknown field", args: []string{"team:core"}, wantErr: ErrInvalidQuery},
+		{name: "unknown status", args: []string{"status:busy"}, wantErr: ErrInvalidQuery},
+		{name: "missing value", args: []string{"city:"}, wantErr: ErrInvalidQuery},
+		{name: "unterminated quote", args: []string{`city:"New York`}, wantErr: ErrInvalidQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.args...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := q.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_Match(t *testing.T) {
+	// Thursday 20 Nov 2025, 09:30 UTC: 09:30 in London, 15:00 in Pune,
+	// 04:30 in New York
+	now := time.Date(2025, 11, 20, 9, 30, 0, 0, time.UTC)
+
+	alice := types.Colleague{ID: "a1b2c3", Name: "Alice", City: "London", Timezone: "Europe/London", Tags: types.Tags{"backend", "oncall"}}
+	priya := types.Colleague{ID: "d4e5f6", Name: "Priya", City: "Pune", Timezone: "Asia/Kolkata", Tags: types.Tags{"frontend"}}
+	dev := types.Colleague{ID: "0a0b0c", Name: "Dev", City: "New York", Timezone: "America/New_York"}
+	everyone := []types.Colleague{alice, priya, dev}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "everyone", query: "", want: []string{"Alice", "Priya", "Dev"}},
+		{name: "name substring", query: "li", want: []string{"Alice"}},
+		{name: "city", query: "city:york", want: []string{"Dev"}},
+		{name: "timezone pattern spans slashes", query: "tz:america/*", want: []string{"Dev"}},
+		{name: "single character wildcard", query: "name:?ev", want: []string{"Dev"}},
+		{name: "tag is exact", query: "tag:back", want: nil},
+		{name: "tag pattern", query: "tag:back*", want: []string{"Alice"}},
+		{name: "id", query: "id:D4E5F6", want: []string{"Priya"}},
+		{name: "status", query: "status:work", want: []string{"Alice", "Priya"}},
+		{name: "status alternatives", query: "status:off,extended", want: []string{"Dev"}},
+		{name: "every term must match", query: "status:work tag:oncall", want: []string{"Alice"}},
+		{name: "negated term", query: "-tz:europe/*", want: []string{"Priya", "Dev"}},
+		{name: "negated tag keeps untagged colleagues", query: "!tag:oncall", want: []string{"Priya", "Dev"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			var got []string
+			for _, c := range everyone {
+				if q.Match(c, now) {
+					got = append(got, c.Name)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestQuery_WithStatuses(t *testing.T) {
+	base, err := ParseQuery("tag:oncall")
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+		wantErr  error
+	}{
+		{name: "none", statuses: nil, want: "tag:oncall"},
+		{name: "repeated", statuses: []string{"work", "Extended"}, want: "tag:oncall status:work,extended"},
+		{name: "comma separated", statuses: []string{"off,day-off"}, want: `tag:oncall status:off,"day off"`},
+		{name: "unknown", statuses: []string{"busy"}, wantErr: ErrInvalidQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := base.WithStatuses(tt.statuses...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := q.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := base.String(); got != "tag:oncall" {
+		t.Errorf("base query changed to %q", got)
+	}
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+const (
+	defaultBackupLimit = 10
+	backupTimeFormat   = "20060102T150405.000000000Z"
+)
+
+var ErrBackupNotFound = errors.New("backup not found")
+
+// Backup is a snapshot of the colleagues file taken before it was replaced
+type Backup struct {
+	Name      string
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// Backups returns the available snapshots, newest first
+func (m *Manager) Backups() ([]Backup, error) {
+	entries, err := os.ReadDir(m.backupDir())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	prefix, ext := m.backupPrefix()
+	var backups []Backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		created, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, Backup{
+			Name:      name,
+			Path:      filepath.Join(m.backupDir(), name),
+			CreatedAt: created,
+			Size:      info.Size(),
+		})
+	}
+
+	slices.SortFunc(backups, func(a, b Backup) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return backups, nil
+}
+
+// LoadBackup reads and validates a snapshot, referenced by name or by its
+// 1-based position in Backups
+func (m *Manager) LoadBackup(ref string) (Backup, *types.ColleagueList, error) {
+	backup, err := m.findBackup(ref)
+	if err != nil {
+		return Backup{}, nil, err
+	}
+
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return Backup{}, nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	doc, _, err := decodeDocument(data)
+	if err != nil {
+		return Backup{}, nil, fmt.Errorf("backup %s: %w", backup.Name, err)
+	}
+
+	cl := &doc.Colleagues
+	if _, err := cl.AssignMissingIDs(); err != nil {
+		return Backup{}, nil, err
+	}
+	if err := cl.Validate(); err != nil {
+		return Backup{}, nil, fmt.Errorf("backup %s, %w", backup.Name, err)
+	}
+
+	return backup, cl, nil
+}
+
+// Restore replaces the colleagues file with a validated snapshot. The current
+// file is itself backed up first, so a restore can be undone
+func (m *Manager) Restore(ref string) (Backup, *types.ColleagueList, error) {
+	backup, cl, err := m.LoadBackup(ref)
+	if err != nil {
+		return Backup{}, nil, err
+	}
+
+	unlock, err := m.lock()
+	if err != nil {
+		return Backup{}, nil, err
+	}
+	defer unlock()
+
+	if err := m.save(cl); err != nil {
+		return Backup{}, nil, fmt.Errorf("failed to restore backup: %w", err)
+	}
+	return backup, cl, nil
+}
+
+func (m *Manager) findBackup(ref string) (Backup, error) {
+	backups, err := m.Backups()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	if idx, err := strconv.Atoi(ref); err == nil {
+		if idx < 1 || idx > len(backups) {
+			return Backup{}, fmt.Errorf("%w: %d (there are %d backups)", ErrBackupNotFound, idx, len(backups))
+		}
+		return backups[idx-1], nil
+	}
+
+	for _, b := range backups {
+		if b.Name == ref {
+			return b, nil
+		}
+	}
+	return Backup{}, fmt.Errorf("%w: %q", ErrBackupNotFound, ref)
+}
+
+// snapshot copies the current colleagues file into the backups folder and
+// prunes the oldest snapshots beyond the limit. It does nothing when the file
+// does not exist yet
+func (m *Manager) snapshot() error {
+	data, err := os.ReadFile(m.filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.backupDir(), 0700); err != nil {
+		return fmt.Errorf("failed to create backups folder: %w", err)
+	}
+
+	prefix, ext := m.backupPrefix()
+	name := prefix + time.Now().UTC().Format(backupTimeFormat) + ext
+	if err := WriteFileAtomic(filepath.Join(m.backupDir(), name), data, 0600); err != nil {
+		return err
+	}
+
+	return m.pruneBackups()
+}
+
+func (m *Manager) pruneBackups() error {
+	limit := m.backupLimit
+	if limit == 0 {
+		limit = defaultBackupLimit
+	}
+
+	backups, err := m.Backups()
+	if err != nil {
+		return err
+	}
+
+	for i := limit; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) backupDir() string {
+	if m.stateDir != "" {
+		return filepath.Join(m.stateDir, "backups")
+	}
+	return filepath.Join(filepath.Dir(m.filePath), "backups")
+}
+
+// backupPrefix returns the file name prefix and extension of snapshots, e.g.
+// "colleagues-" and ".json"
+func (m *Manager) backupPrefix() (string, string) {
+	base := filepath.Base(m.filePath)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
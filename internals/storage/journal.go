@@ -0,0 +1,405 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+// Operations recorded in the journal
+const (
+	OpAdd    = "add"
+	OpRemove = "remove"
+	OpEdit   = "edit"
+	OpImport = "import"
+	OpUndo   = "undo"
+	OpRedo   = "redo"
+)
+
+const (
+	// journalLimit is how many changes can be undone, as many as the backups kept
+	journalLimit = defaultBackupLimit
+	// journalCompactAt is how many entries the journal grows to before a
+	// mutation compacts it to the changes that can still be undone
+	journalCompactAt = 4 * journalLimit
+)
+
+var (
+	ErrNothingToUndo   = errors.New("nothing to undo")
+	ErrNothingToRedo   = errors.New("nothing to redo")
+	ErrJournalDiverged = errors.New("colleagues file has changed since the journal was written")
+)
+
+// Change describes a single mutation of the list: the colleague at the 1-based
+// Position before and after it. Before is nil for an add, After for a remove.
+// An import, which may touch many colleagues, carries the whole list before
+// and after it instead
+type Change struct {
+	Op         string
+	Position   int
+	Before     *types.Colleague
+	After      *types.Colleague
+	BeforeList types.ColleagueList
+	AfterList  types.ColleagueList
+}
+
+// JournalEntry is a line of the journal. Mutations carry the change they made,
+// undo and redo entries the sequence number of the mutation they replayed.
+// The hashes identify the whole list before and after the entry was applied
+type JournalEntry struct {
+	Seq        int                 `json:"seq"`
+	Op         string              `json:"op"`
+	Time       time.Time           `json:"time"`
+	Position   int                 `json:"position,omitempty"`
+	Before     *types.Colleague    `json:"before,omitempty"`
+	After      *types.Colleague    `json:"after,omitempty"`
+	BeforeList types.ColleagueList `json:"before_list,omitempty"`
+	AfterList  types.ColleagueList `json:"after_list,omitempty"`
+	Target     int                 `json:"target,omitempty"`
+	BeforeHash string              `json:"before_hash"`
+	AfterHash  string              `json:"after_hash"`
+}
+
+// Colleague returns the colleague the entry is about, the one it left behind
+// if there is one
+func (e JournalEntry) Colleague() types.Colleague {
+	if e.After != nil {
+		return *e.After
+	}
+	if e.Before != nil {
+		return *e.Before
+	}
+	return types.Colleague{}
+}
+
+// Summary describes the mutation, e.g. "add of Alice", or "import" for an
+// import, which is about the whole list
+func (e JournalEntry) Summary() string {
+	if e.Op == OpImport {
+		return e.Op
+	}
+	return fmt.Sprintf("%s of %s", e.Op, e.Colleague().Name)
+}
+
+// Record is Update for mutations that can be undone: fn reports the change it
+// made, which is appended to the journal before the list is saved
+func (m *Manager) Record(fn func(cl *types.ColleagueList) (Change, error)) error {
+	unlock, err := m.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cl, err := m.load()
+	if err != nil {
+		return fmt.Errorf("failed to load colleagues: %w", err)
+	}
+
+	before, err := hashList(cl)
+	if err != nil {
+		return err
+	}
+
+	change, err := fn(cl)
+	if err != nil {
+		return err
+	}
+
+	after, err := hashList(cl)
+	if err != nil {
+		return err
+	}
+
+	entries, err := m.readJournal()
+	if err != nil {
+		return err
+	}
+
+	return m.commit(cl, entries, JournalEntry{
+		Op:         change.Op,
+		Position:   change.Position,
+		Before:     change.Before,
+		After:      change.After,
+		BeforeList: change.BeforeList,
+		AfterList:  change.AfterList,
+		BeforeHash: before,
+		AfterHash:  after,
+	})
+}
+
+// Undo reverses the most recent mutation that has not been undone yet and
+// returns its journal entry
+func (m *Manager) Undo() (JournalEntry, error) {
+	return m.replay(OpUndo)
+}
+
+// Redo reapplies the most recently undone mutation and returns its journal
+// entry. Any new mutation clears what can be redone
+func (m *Manager) Redo() (JournalEntry, error) {
+	return m.replay(OpRedo)
+}
+
+// replay walks the journal to find the mutation to undo or redo and applies
+// it, refusing if the list no longer matches the state the journal expects
+func (m *Manager) replay(op string) (JournalEntry, error) {
+	unlock, err := m.lock()
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	defer unlock()
+
+	entries, err := m.readJournal()
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	done, undone := journalStacks(entries)
+
+	var target JournalEntry
+	var want, next string
+	switch op {
+	case OpUndo:
+		if len(done) == 0 {
+			return JournalEntry{}, ErrNothingToUndo
+		}
+		target = done[len(done)-1]
+		want, next = target.AfterHash, target.BeforeHash
+	case OpRedo:
+		if len(undone) == 0 {
+			return JournalEntry{}, ErrNothingToRedo
+		}
+		target = undone[len(undone)-1]
+		want, next = target.BeforeHash, target.AfterHash
+	}
+
+	cl, err := m.load()
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to load colleagues: %w", err)
+	}
+
+	current, err := hashList(cl)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	if current != want {
+		return JournalEntry{}, fmt.Errorf("%w: cannot %s %s", ErrJournalDiverged, op, target.Summary())
+	}
+
+	if err := applyChange(cl, target, op == OpUndo); err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to %s journal entry %d: %w", op, target.Seq, err)
+	}
+
+	if result, err := hashList(cl); err != nil {
+		return JournalEntry{}, err
+	} else if result != next {
+		return JournalEntry{}, fmt.Errorf("%w: %s of journal entry %d gives a different list", ErrJournalDiverged, op, target.Seq)
+	}
+
+	err = m.commit(cl, entries, JournalEntry{
+		Op:         op,
+		Target:     target.Seq,
+		BeforeHash: current,
+		AfterHash:  next,
+	})
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	return target, nil
+}
+
+// journalStacks replays the journal and returns the last journalLimit
+// mutations that can be undone and those that can be redone, the next one
+// last in both
+func journalStacks(entries []JournalEntry) (done, undone []JournalEntry) {
+	for _, e := range entries {
+		switch e.Op {
+		case OpUndo:
+			if len(done) > 0 {
+				undone = append(undone, done[len(done)-1])
+				done = done[:len(done)-1]
+			}
+		case OpRedo:
+			if len(undone) > 0 {
+				done = append(done, undone[len(undone)-1])
+				undone = undone[:len(undone)-1]
+			}
+		default:
+			done = append(done, e)
+			if len(done) > journalLimit {
+				done = done[len(done)-journalLimit:]
+			}
+			undone = nil
+		}
+	}
+	return done, undone
+}
+
+// applyChange applies the mutation in e to cl, or its inverse when reverse is set
+func applyChange(cl *types.ColleagueList, e JournalEntry, reverse bool) error {
+	switch {
+	case e.Op == OpAdd && e.After != nil:
+		if reverse {
+			_, err := cl.Remove(e.Position)
+			return err
+		}
+		return cl.Insert(e.Position, *e.After)
+	case e.Op == OpRemove && e.Before != nil:
+		if reverse {
+			return cl.Insert(e.Position, *e.Before)
+		}
+		_, err := cl.Remove(e.Position)
+		return err
+	case e.Op == OpEdit && e.Before != nil && e.After != nil:
+		if reverse {
+			return cl.Update(e.Position, *e.Before)
+		}
+		return cl.Update(e.Position, *e.After)
+	case e.Op == OpImport:
+		if reverse {
+			*cl = slices.Clone(e.BeforeList)
+		} else {
+			*cl = slices.Clone(e.AfterList)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported or incomplete %q entry", e.Op)
+}
+
+func (m *Manager) journalPath() string {
+	return m.statePath(".journal")
+}
+
+// readJournal returns every entry of the journal, which is empty if the file
+// does not exist yet
+func (m *Manager) readJournal() ([]JournalEntry, error) {
+	data, err := os.ReadFile(m.journalPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse journal line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// commit numbers and timestamps e, appends it to the journal after entries
+// and saves cl, so that every saved change can be undone. The entry is taken
+// back if cl cannot be saved. Once the journal has grown past
+// journalCompactAt entries, a mutation compacts it
+func (m *Manager) commit(cl *types.ColleagueList, entries []JournalEntry, e JournalEntry) error {
+	e.Seq = 1
+	if len(entries) > 0 {
+		e.Seq = entries[len(entries)-1].Seq + 1
+	}
+	e.Time = time.Now().UTC()
+
+	size, err := m.appendJournal(e)
+	if err != nil {
+		return err
+	}
+
+	if err := m.save(cl); err != nil {
+		if terr := os.Truncate(m.journalPath(), size); terr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to take back journal entry %d: %w", e.Seq, terr))
+		}
+		return fmt.Errorf("failed to save colleagues: %w", err)
+	}
+
+	entries = append(entries, e)
+	if e.Op != OpUndo && e.Op != OpRedo && len(entries) > journalCompactAt {
+		// A new mutation discards what could be redone, so only the changes
+		// that can be undone are kept. The journal is complete without
+		// compacting, so a failure is left for the next mutation to retry
+		done, _ := journalStacks(entries)
+		_ = m.writeJournal(done)
+	}
+	return nil
+}
+
+// appendJournal appends e to the journal and returns the size the journal had
+// before, to take e back by truncating it
+func (m *Manager) appendJournal(e JournalEntry) (int64, error) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+
+	f, err := os.OpenFile(m.journalPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	// A partly written line would make the whole journal unreadable
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Truncate(info.Size())
+		return 0, fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Truncate(info.Size())
+		return 0, fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// writeJournal replaces the journal with entries, atomically
+func (m *Manager) writeJournal(entries []JournalEntry) error {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode journal entry: %w", err)
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	if err := WriteFileAtomic(m.journalPath(), buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// hashList identifies the content of cl, ignoring when it was saved
+func hashList(cl *types.ColleagueList) (string, error) {
+	list := *cl
+	if list == nil {
+		list = types.ColleagueList{}
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash colleagues: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Environment variables consulted by Locate
+const (
+	EnvHome          = "TEAMTIME_HOME"
+	EnvXDGConfigHome = "XDG_CONFIG_HOME"
+)
+
+const (
+	fileName = "colleagues.json"
+	// migrationDeclinedFile marks a legacy directory whose owner chose not to
+	// move it to the XDG location, so that they are only asked once
+	migrationDeclinedFile = ".no-migrate"
+)
+
+var ErrMigrationTarget = errors.New("migration target already exists")
+
+// Location is where the colleagues file was resolved to
+type Location struct {
+	FilePath string
+	// Source names what chose FilePath: "--config", "$TEAMTIME_HOME",
+	// "$XDG_CONFIG_HOME" or "~/.teamtime"
+	Source string
+	// MigrateTo is set when FilePath is the legacy file and it can be moved to
+	// the XDG location, which is where it would live if it did not exist
+	MigrateTo string
+}
+
+// Locate resolves the colleagues file, in order of preference, from the
+// --config flag, $TEAMTIME_HOME, $XDG_CONFIG_HOME/teamtime and the legacy
+// ~/.teamtime. A legacy directory is kept in use until it is migrated, even
+// when $XDG_CONFIG_HOME is set. homeDir is only needed for the legacy location
+func Locate(configFlag, homeDir string, getenv func(string) string) (Location, error) {
+	if configFlag != "" {
+		return Location{FilePath: configFlag, Source: "--config"}, nil
+	}
+
+	if dir := getenv(EnvHome); dir != "" {
+		return Location{FilePath: filepath.Join(dir, fileName), Source: "$" + EnvHome}, nil
+	}
+
+	var xdg string
+	if dir := getenv(EnvXDGConfigHome); dir != "" {
+		xdg = filepath.Join(dir, "teamtime", fileName)
+		if _, err := os.Stat(filepath.Dir(xdg)); err == nil {
+			return Location{FilePath: xdg, Source: "$" + EnvXDGConfigHome}, nil
+		}
+	}
+
+	if homeDir == "" {
+		if xdg != "" {
+			return Location{FilePath: xdg, Source: "$" + EnvXDGConfigHome}, nil
+		}
+		return Location{}, ErrMissingHomeDir
+	}
+
+	legacyDir := filepath.Join(homeDir, ".teamtime")
+	legacy := Location{FilePath: filepath.Join(legacyDir, fileName), Source: "~/.teamtime"}
+	// The directory may only hold profiles, without a default roster
+	if _, err := os.Stat(legacyDir); err != nil {
+		if xdg != "" {
+			return Location{FilePath: xdg, Source: "$" + EnvXDGConfigHome}, nil
+		}
+		return legacy, nil
+	}
+
+	if xdg != "" {
+		if _, err := os.Stat(filepath.Join(legacyDir, migrationDeclinedFile)); err != nil {
+			legacy.MigrateTo = xdg
+		}
+	}
+	return legacy, nil
+}
+
+// MigrateLegacy moves the directory holding the legacy file in loc, with its
+// backups and journal, to the directory of loc.MigrateTo
+func MigrateLegacy(loc Location) error {
+	from := filepath.Dir(loc.FilePath)
+	to := filepath.Dir(loc.MigrateTo)
+
+	if _, err := os.Stat(to); err == nil {
+		entries, err := os.ReadDir(to)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", to, err)
+		}
+		if len(entries) > 0 {
+			return fmt.Errorf("%w: %s", ErrMigrationTarget, to)
+		}
+		if err := os.Remove(to); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", to, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(to), err)
+	}
+
+	if err := os.Rename(from, to); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+	}
+	return nil
+}
+
+// DeclineMigration records that the legacy directory in loc should stay where
+// it is, so that Locate stops offering to migrate it
+func DeclineMigration(loc Location) error {
+	marker := filepath.Join(filepath.Dir(loc.FilePath), migrationDeclinedFile)
+	if err := os.WriteFile(marker, nil, 0600); err != nil {
+		return fmt.Errorf("failed to record migration choice: %w", err)
+	}
+	return nil
+}
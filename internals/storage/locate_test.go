@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func TestLocate(t *testing.T) {
+	writeFile := func(t *testing.T, path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("[]"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		config        string
+		env           map[string]string
+		setup         func(t *testing.T, home string)
+		wantPath      string
+		wantSource    string
+		wantMigrateTo string
+	}{
+		{
+			name:       "legacy by default",
+			wantPath:   ".teamtime/colleagues.json",
+			wantSource: "~/.teamtime",
+		},
+		{
+			name:       "config flag wins",
+			config:     "team.json",
+			env:        map[string]string{EnvHome: "tth", EnvXDGConfigHome: "xdg"},
+			wantPath:   "team.json",
+			wantSource: "--config",
+		},
+		{
+			name:       "TEAMTIME_HOME before XDG",
+			env:        map[string]string{EnvHome: "tth", EnvXDGConfigHome: "xdg"},
+			wantPath:   "tth/colleagues.json",
+			wantSource: "$TEAMTIME_HOME",
+		},
+		{
+			name:       "XDG for new installs",
+			env:        map[string]string{EnvXDGConfigHome: "xdg"},
+			wantPath:   "xdg/teamtime/colleagues.json",
+			wantSource: "$XDG_CONFIG_HOME",
+		},
+		{
+			name: "XDG file before legacy file",
+			env:  map[string]string{EnvXDGConfigHome: "xdg"},
+			setup: func(t *testing.T, home string) {
+				writeFile(t, filepath.Join(home, "xdg", "teamtime", "colleagues.json"))
+				writeFile(t, filepath.Join(home, ".teamtime", "colleagues.json"))
+			},
+			wantPath:   "xdg/teamtime/colleagues.json",
+			wantSource: "$XDG_CONFIG_HOME",
+		},
+		{
+			name: "legacy file offers migration",
+			env:  map[string]string{EnvXDGConfigHome: "xdg"},
+			setup: func(t *testing.T, home string) {
+				writeFile(t, filepath.Join(home, ".teamtime", "colleagues.json"))
+			},
+			wantPath:      ".teamtime/colleagues.json",
+			wantSource:    "~/.teamtime",
+			wantMigrateTo: "xdg/teamtime/colleagues.json",
+		},
+		{
+			name: "declined migration is not offered again",
+			env:  map[string]string{EnvXDGConfigHome: "xdg"},
+			setup: func(t *testing.T, home string) {
+				writeFile(t, filepath.Join(home, ".teamtime", "colleagues.json"))
+				writeFile(t, filepath.Join(home, ".teamtime", migrationDeclinedFile))
+			},
+			wantPath:   ".teamtime/colleagues.json",
+			wantSource: "~/.teamtime",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			if tt.setup != nil {
+				tt.setup(t, home)
+			}
+
+			abs := func(p string) string {
+				if p == "" {
+					return ""
+				}
+				return filepath.Join(home, filepath.FromSlash(p))
+			}
+			getenv := func(key string) string {
+				return abs(tt.env[key])
+			}
+
+			loc, err := Locate(abs(tt.config), home, getenv)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if loc.FilePath != abs(tt.wantPath) {
+				t.Errorf("got path %q, want %q", loc.FilePath, abs(tt.wantPath))
+			}
+			if loc.Source != tt.wantSource {
+				t.Errorf("got source %q, want %q", loc.Source, tt.wantSource)
+			}
+			if loc.MigrateTo != abs(tt.wantMigrateTo) {
+				t.Errorf("got migrate to %q, want %q", loc.MigrateTo, abs(tt.wantMigrateTo))
+			}
+		})
+	}
+
+	t.Run("no home directory", func(t *testing.T) {
+		_, err := Locate("", "", func(string) string { return "" })
+		if !errors.Is(err, ErrMissingHomeDir) {
+			t.Errorf("expected %v, got %v", ErrMissingHomeDir, err)
+		}
+	})
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	setup := func(t *testing.T) Location {
+		t.Helper()
+		home := t.TempDir()
+		m, err := NewManager(home)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+		if err := m.EnsureFolder(); err != nil {
+			t.Fatalf("failed to ensure folder: %v", err)
+		}
+		for _, name := range []string{"Alice", "Bob"} {
+			err := m.Update(func(cl *types.ColleagueList) error {
+				cl.Add(mustNewColleague(t, name, "London", "Europe/London"))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("update failed: %v", err)
+			}
+		}
+
+		return Location{
+			FilePath:  m.GetFilePath(),
+			MigrateTo: filepath.Join(home, "xdg", "teamtime", "colleagues.json"),
+		}
+	}
+
+	t.Run("moves the file with its backups", func(t *testing.T) {
+		loc := setup(t)
+		if err := MigrateLegacy(loc); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Dir(loc.FilePath)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected legacy directory to be gone, got %v", err)
+		}
+
+		m, err := NewManagerForFile("", loc.MigrateTo)
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+		cl, err := m.Load()
+		if err != nil {
+			t.Fatalf("failed to load migrated file: %v", err)
+		}
+		if len(*cl) != 2 {
+			t.Errorf("expected 2 colleagues, got %d", len(*cl))
+		}
+
+		backups, err := m.Backups()
+		if err != nil {
+			t.Fatalf("failed to list backups: %v", err)
+		}
+		if len(backups) != 1 {
+			t.Errorf("expected 1 backup, got %d", len(backups))
+		}
+	})
+
+	t.Run("refuses to overwrite a non-empty directory", func(t *testing.T) {
+		loc := setup(t)
+		other := filepath.Join(filepath.Dir(loc.MigrateTo), "notes.txt")
+		if err := os.MkdirAll(filepath.Dir(other), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(other, []byte("keep"), 0600); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+
+		if err := MigrateLegacy(loc); !errors.Is(err, ErrMigrationTarget) {
+			t.Errorf("expected %v, got %v", ErrMigrationTarget, err)
+		}
+		if _, err := os.Stat(loc.FilePath); err != nil {
+			t.Errorf("expected legacy file to stay, got %v", err)
+		}
+	})
+
+	t.Run("declining stops the offer", func(t *testing.T) {
+		loc := setup(t)
+		if err := DeclineMigration(loc); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		home := filepath.Dir(filepath.Dir(loc.FilePath))
+		getenv := func(key string) string {
+			if key == EnvXDGConfigHome {
+				return filepath.Join(home, "xdg")
+			}
+			return ""
+		}
+		got, err := Locate("", home, getenv)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.MigrateTo != "" {
+			t.Errorf("expected no migration offer, got %q", got.MigrateTo)
+		}
+	})
+}
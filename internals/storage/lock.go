@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultLockTimeout = 5 * time.Second
+	lockRetryInterval  = 20 * time.Millisecond
+)
+
+var ErrLockTimeout = errors.New("colleagues file is locked by another teamtime process")
+
+// errLocked is returned by tryLock when another process holds the lock
+var errLocked = errors.New("lock is held")
+
+// acquireLock takes the advisory lock at path, retrying until timeout. The
+// returned function releases it
+func acquireLock(path string, timeout time.Duration) (func() error, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		unlock, err := tryLock(path)
+		if err == nil {
+			return unlock, nil
+		}
+
+		if !errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w (waited %s), try again", ErrLockTimeout, timeout)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package storage
+
+// tryLock is a no-op on platforms without file locking support
+func tryLock(path string) (func() error, error) {
+	return func() error { return nil }, nil
+}
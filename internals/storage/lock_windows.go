@@ -0,0 +1,37 @@
+//go:build windows
+
+package storage
+
+import (
+	"errors"
+	"syscall"
+)
+
+const errorSharingViolation syscall.Errno = 32
+
+// tryLock opens path without sharing, so that no other process can open it
+// until the handle is closed
+func tryLock(path string) (func() error, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := syscall.CreateFile(name,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+		0,
+		nil,
+		syscall.OPEN_ALWAYS,
+		syscall.FILE_ATTRIBUTE_NORMAL,
+		0)
+	if err != nil {
+		if errors.Is(err, errorSharingViolation) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	return func() error {
+		return syscall.CloseHandle(h)
+	}, nil
+}
@@ -1,72 +1,260 @@
 package storage
 
 import (
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/matteo-gildone/teamtime/internals/types"
 )
 
 const maxFileSize = 10 * 1024 * 1024 // 10MB
 
-var ErrMissingHomeDir = errors.New("'homeDir' must not be empty")
+var (
+	ErrMissingHomeDir  = errors.New("'homeDir' must not be empty")
+	ErrMissingFilePath = errors.New("'filePath' must not be empty")
+)
 
 type Manager struct {
 	homeDir  string
 	filePath string
+	// lockTimeout is how long to wait for other processes to release the
+	// lock, defaultLockTimeout when zero
+	lockTimeout time.Duration
+	// backupLimit is how many snapshots to keep, defaultBackupLimit when zero
+	backupLimit int
+	// stateDir holds the lock, journal and backups of the file, which sit
+	// next to it when empty
+	stateDir string
+}
+
+// writeData writes data to a temporary file during Save. Tests replace it to
+// simulate writes failing part way through
+var writeData = func(f *os.File, data []byte) error {
+	_, err := f.Write(data)
+	return err
 }
 
+// Save writes the list atomically: the data goes to a temporary file in the
+// same directory, which is synced and then renamed over the original. A crash
+// or failed write leaves the previous file untouched
 func (m *Manager) Save(cl *types.ColleagueList) error {
-	js, err := json.Marshal(cl)
+	unlock, err := m.lock()
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	return m.save(cl)
+}
+
+// Update loads the list, applies fn and saves the result, holding the lock
+// for the whole cycle so that concurrent processes cannot lose each other's
+// changes. Nothing is saved if fn returns an error, which is returned as is
+func (m *Manager) Update(fn func(cl *types.ColleagueList) error) error {
+	unlock, err := m.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cl, err := m.load()
+	if err != nil {
+		return fmt.Errorf("failed to load colleagues: %w", err)
+	}
+
+	if err := fn(cl); err != nil {
+		return err
+	}
+
+	if err := m.save(cl); err != nil {
+		return fmt.Errorf("failed to save colleagues: %w", err)
+	}
+	return nil
+}
+
+// lock takes the advisory lock guarding the colleagues file
+func (m *Manager) lock() (func() error, error) {
+	timeout := m.lockTimeout
+	if timeout == 0 {
+		timeout = defaultLockTimeout
+	}
+	if m.stateDir != "" {
+		if err := os.MkdirAll(m.stateDir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create directory %s: %w", m.stateDir, err)
+		}
+	}
+	return acquireLock(m.lockPath(), timeout)
+}
+
+// statePath returns the path of a file kept alongside the colleagues file,
+// named after it with suffix, e.g. colleagues.json.lock
+func (m *Manager) statePath(suffix string) string {
+	if m.stateDir == "" {
+		return m.filePath + suffix
+	}
+	return filepath.Join(m.stateDir, filepath.Base(m.filePath)+suffix)
+}
+
+// migrationBackupPath is where the file is copied before upgrading it from
+// schema version
+func (m *Manager) migrationBackupPath(version int) string {
+	return m.statePath(fmt.Sprintf(".v%d.bak", version))
+}
+
+func (m *Manager) lockPath() string {
+	return m.statePath(".lock")
+}
+
+func (m *Manager) save(cl *types.ColleagueList) error {
+	js, err := encodeDocument(cl)
+	if err != nil {
+		return err
+	}
+
+	if err := m.snapshot(); err != nil {
+		return fmt.Errorf("failed to back up file: %w", err)
+	}
+
+	return WriteFileAtomic(m.filePath, js, 0600)
+}
+
+// WriteFileAtomic writes data to path through a temporary file in the same
+// directory, which is synced and renamed over path, so that readers see
+// either the old content or the new one
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err = tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	if err = writeData(tmp, data); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
 
-	return os.WriteFile(m.filePath, js, 0600)
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
+	}
+
+	syncDir(dir)
+	return nil
 }
 
+// syncDir flushes the directory entry of a renamed file to disk. It is best
+// effort, as some platforms do not support syncing directories
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	_ = d.Sync()
+}
+
+// Load reads and validates the list without writing anything, so that it
+// only needs read access. Saves replace the file atomically, so no lock is
+// needed either. A file from an older schema, or with colleagues missing an
+// ID, is upgraded in memory and written back by the next change
 func (m *Manager) Load() (*types.ColleagueList, error) {
+	cl, _, _, err := m.read()
+	return cl, err
+}
+
+// load reads the list for a change that is about to be saved, first copying a
+// file from an older schema aside so that the upgrade can be reverted. The
+// caller must hold the lock
+func (m *Manager) load() (*types.ColleagueList, error) {
+	cl, version, file, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if version < SchemaVersion && len(file) > 0 {
+		backup := m.migrationBackupPath(version)
+		if err := WriteFileAtomic(backup, file, 0600); err != nil {
+			return nil, fmt.Errorf("failed to back up file before migration: %w", err)
+		}
+	}
+	return cl, nil
+}
+
+// read decodes the colleagues file and returns the list along with the schema
+// version and the raw content of the file
+func (m *Manager) read() (*types.ColleagueList, int, []byte, error) {
 	err := m.validateSize()
 	if err != nil {
-		return nil, fmt.Errorf("failed to validate file size: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed to validate file size: %w", err)
 	}
 
 	file, err := os.ReadFile(m.filePath)
 
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return types.NewColleagues(), nil
+			return types.NewColleagues(), SchemaVersion, nil, nil
 		}
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	if len(file) == 0 {
-		return types.NewColleagues(), nil
+		return types.NewColleagues(), SchemaVersion, nil, nil
 	}
 
-	cl := types.NewColleagues()
-	if err := json.Unmarshal(file, cl); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	doc, version, err := decodeDocument(file)
+	if err != nil {
+		if errors.Is(err, ErrNewerSchema) {
+			return nil, 0, nil, err
+		}
+		return nil, 0, nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	cl := &doc.Colleagues
 
-	for i, c := range *cl {
-		if err = c.Validate(); err != nil {
-			return nil, fmt.Errorf("colleague at index %d: %w", i+1, err)
-		}
+	// Files written before colleagues had IDs get them here, and keep them
+	// once the list is next saved
+	if _, err := cl.AssignMissingIDs(); err != nil {
+		return nil, 0, nil, err
 	}
 
-	return cl, nil
+	if err := cl.Validate(); err != nil {
+		return nil, 0, nil, err
+	}
+
+	return cl, version, file, nil
 }
+
 func (m *Manager) Exists() bool {
 	_, err := os.Stat(m.filePath)
 	return err == nil
 }
 
+// EnsureFolder creates the directory holding the colleagues file
 func (m *Manager) EnsureFolder() error {
-	configDir := filepath.Join(m.homeDir, ".teamtime")
+	configDir := filepath.Dir(m.filePath)
 
 	if _, err := os.Stat(configDir); err == nil {
 		return nil
@@ -82,9 +270,14 @@ func (m *Manager) GetFilePath() string {
 	return m.filePath
 }
 
+// GetRelativeFilePath returns the path of the colleagues file relative to the
+// home directory, or the full path when it lives elsewhere
 func (m *Manager) GetRelativeFilePath() string {
+	if m.homeDir == "" {
+		return m.filePath
+	}
 	rel, err := filepath.Rel(m.homeDir, m.filePath)
-	if err != nil {
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
 		return m.filePath
 	}
 	return filepath.Join("~", rel)
@@ -105,6 +298,8 @@ func (m *Manager) validateSize() error {
 	return nil
 }
 
+// NewManager returns a manager for the legacy location of the colleagues
+// file, ~/.teamtime/colleagues.json
 func NewManager(homeDir string) (*Manager, error) {
 	if homeDir == "" {
 		return nil, ErrMissingHomeDir
@@ -120,3 +315,50 @@ func NewManager(homeDir string) (*Manager, error) {
 		filePath: configPath,
 	}, nil
 }
+
+// NewManagerForFile returns a manager for the colleagues file at filePath.
+// homeDir is only used to display the path and may be empty
+func NewManagerForFile(homeDir, filePath string) (*Manager, error) {
+	if filePath == "" {
+		return nil, ErrMissingFilePath
+	}
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", filePath, err)
+	}
+
+	if homeDir != "" {
+		homeDir = filepath.Clean(homeDir)
+	}
+
+	return &Manager{
+		homeDir:  homeDir,
+		filePath: absPath,
+	}, nil
+}
+
+// NewManagerForExternalFile returns a manager for a colleagues file that may
+// live outside dataDir, the teamtime directory, such as one given with
+// --config. The lock, journal and backups of a file outside dataDir are kept
+// in dataDir/files, in a directory named after the absolute path of the file,
+// so that nothing but the file itself is written next to it
+func NewManagerForExternalFile(homeDir, filePath, dataDir string) (*Manager, error) {
+	m, err := NewManagerForFile(homeDir, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	dataDir, err = filepath.Abs(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dataDir, err)
+	}
+	rel, err := filepath.Rel(dataDir, m.filePath)
+	if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return m, nil
+	}
+
+	sum := sha256.Sum256([]byte(m.filePath))
+	name := strings.TrimSuffix(filepath.Base(m.filePath), filepath.Ext(m.filePath))
+	m.stateDir = filepath.Join(dataDir, "files", name+"-"+hex.EncodeToString(sum[:4]))
+	return m, nil
+}
@@ -3,10 +3,14 @@ package storage
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/matteo-gildone/teamtime/internals/types"
 )
@@ -86,6 +90,107 @@ func TestManager_GetRelativeFilePath(t *testing.T) {
 	}
 }
 
+func TestNewManagerForFile(t *testing.T) {
+	homeDir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "team", "roster.json")
+
+	tests := []struct {
+		name         string
+		homeDir      string
+		filePath     string
+		wantRelative string
+		wantErr      error
+	}{
+		{name: "inside home", homeDir: homeDir, filePath: filepath.Join(homeDir, "team.json"), wantRelative: filepath.Join("~", "team.json")},
+		{name: "outside home", homeDir: homeDir, filePath: outside, wantRelative: outside},
+		{name: "no home", filePath: outside, wantRelative: outside},
+		{name: "missing path", homeDir: homeDir, wantErr: ErrMissingFilePath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewManagerForFile(tt.homeDir, tt.filePath)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := m.GetRelativeFilePath(); got != tt.wantRelative {
+				t.Errorf("got %q, want %q", got, tt.wantRelative)
+			}
+
+			if err := m.EnsureFolder(); err != nil {
+				t.Fatalf("ensureFolder failed %v", err)
+			}
+			if _, err := os.Stat(filepath.Dir(tt.filePath)); err != nil {
+				t.Errorf("expected folder to exist, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewManagerForExternalFile(t *testing.T) {
+	t.Run("keeps state in the data directory", func(t *testing.T) {
+		dataDir := t.TempDir()
+		teamDir := t.TempDir()
+		m, err := NewManagerForExternalFile("", filepath.Join(teamDir, "team.json"), dataDir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, name := range []string{"Alice", "Bob"} {
+			err := m.Record(func(cl *types.ColleagueList) (Change, error) {
+				c := mustNewColleague(t, name, "London", "Europe/London")
+				cl.Add(c)
+				return Change{Op: OpAdd, Position: len(*cl), After: &c}, nil
+			})
+			if err != nil {
+				t.Fatalf("record failed: %v", err)
+			}
+		}
+
+		entries, err := os.ReadDir(teamDir)
+		if err != nil {
+			t.Fatalf("failed to read directory: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Name() != "team.json" {
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Name())
+			}
+			t.Errorf("expected only team.json next to the file, got %v", got)
+		}
+
+		if !strings.HasPrefix(m.journalPath(), filepath.Join(dataDir, "files")) {
+			t.Errorf("expected the journal in the data directory, got %s", m.journalPath())
+		}
+		if backups, err := m.Backups(); err != nil || len(backups) != 1 {
+			t.Errorf("expected a backup in the data directory, got %v, %v", backups, err)
+		}
+		if _, err := m.Undo(); err != nil {
+			t.Errorf("expected undo to work, got %v", err)
+		}
+	})
+
+	t.Run("files in the data directory keep state next to them", func(t *testing.T) {
+		dataDir := t.TempDir()
+		filePath := filepath.Join(dataDir, "profiles", "work.json")
+		m, err := NewManagerForExternalFile("", filePath, dataDir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, want := m.lockPath(), filePath+".lock"; got != want {
+			t.Errorf("got lock %s, want %s", got, want)
+		}
+	})
+}
+
 func TestManager_EnsureFolder(t *testing.T) {
 	t.Run("create folder when it doesn't exists", func(t *testing.T) {
 		tempDir := t.TempDir()
@@ -232,14 +337,18 @@ func TestManager_Save(t *testing.T) {
 				t.Fatalf("failed to read file: %v", err)
 			}
 
-			var loaded types.ColleagueList
+			var loaded document
 			err = json.Unmarshal(data, &loaded)
 			if err != nil {
 				t.Fatalf("failed to unmarshal: %v", err)
 			}
 
-			if len(loaded) != tt.expectedLength {
-				t.Errorf("expected %d colleagues, got %d", tt.expectedLength, len(loaded))
+			if loaded.Version != SchemaVersion {
+				t.Errorf("expected version %d, got %d", SchemaVersion, loaded.Version)
+			}
+
+			if len(loaded.Colleagues) != tt.expectedLength {
+				t.Errorf("expected %d colleagues, got %d", tt.expectedLength, len(loaded.Colleagues))
 			}
 		})
 	}
@@ -269,15 +378,15 @@ func TestManager_Save(t *testing.T) {
 
 		// Load and verify
 		data, _ := os.ReadFile(testFile)
-		var loaded types.ColleagueList
+		var loaded document
 		json.Unmarshal(data, &loaded)
 
-		if len(loaded) != 1 {
-			t.Errorf("expected 1 colleague, got %d", len(loaded))
+		if len(loaded.Colleagues) != 1 {
+			t.Errorf("expected 1 colleague, got %d", len(loaded.Colleagues))
 		}
 
-		if len(loaded) > 0 && loaded[0].Name != "Bob" {
-			t.Errorf("expected Bob, got %s", loaded[0].Name)
+		if len(loaded.Colleagues) > 0 && loaded.Colleagues[0].Name != "Bob" {
+			t.Errorf("expected Bob, got %s", loaded.Colleagues[0].Name)
 		}
 	})
 
@@ -292,6 +401,152 @@ func TestManager_Save(t *testing.T) {
 	})
 }
 
+func TestManager_Save_Atomic(t *testing.T) {
+	setup := func(t *testing.T) (*Manager, []byte) {
+		t.Helper()
+		tempDir := t.TempDir()
+		m := &Manager{filePath: filepath.Join(tempDir, "colleagues.json")}
+
+		original := types.NewColleagues()
+		original.Add(mustNewColleague(t, "Alice", "London", "Europe/London"))
+		original.Add(mustNewColleague(t, "Bob", "NYC", "America/New_York"))
+		if err := m.Save(original); err != nil {
+			t.Fatalf("initial save failed: %v", err)
+		}
+
+		data, err := os.ReadFile(m.filePath)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		return m, data
+	}
+
+	replaceWriteData := func(t *testing.T, fn func(f *os.File, data []byte) error) {
+		t.Helper()
+		orig := writeData
+		writeData = fn
+		t.Cleanup(func() { writeData = orig })
+	}
+
+	t.Run("partial write keeps original file", func(t *testing.T) {
+		m, before := setup(t)
+		replaceWriteData(t, func(f *os.File, data []byte) error {
+			if !isLiveTempFile(f) {
+				_, err := f.Write(data)
+				return err
+			}
+			if _, err := f.Write(data[:len(data)/2]); err != nil {
+				return err
+			}
+			return errors.New("no space left on device")
+		})
+
+		cl := types.NewColleagues()
+		cl.Add(mustNewColleague(t, "Matteo", "Tokyo", "Asia/Tokyo"))
+		if err := m.Save(cl); err == nil {
+			t.Fatal("expected error from failed write")
+		}
+
+		after, err := os.ReadFile(m.filePath)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+
+		if string(after) != string(before) {
+			t.Errorf("expected original content to be kept, got %q", after)
+		}
+
+		loaded, err := m.Load()
+		if err != nil {
+			t.Fatalf("expected original file to still load, got: %v", err)
+		}
+
+		if len(*loaded) != 2 {
+			t.Errorf("expected 2 colleagues, got %d", len(*loaded))
+		}
+
+		assertNoTempFiles(t, filepath.Dir(m.filePath))
+	})
+
+	t.Run("interrupted write before any data keeps original file", func(t *testing.T) {
+		m, before := setup(t)
+		replaceWriteData(t, func(f *os.File, data []byte) error {
+			if !isLiveTempFile(f) {
+				_, err := f.Write(data)
+				return err
+			}
+			return errors.New("interrupted")
+		})
+
+		if err := m.Save(types.NewColleagues()); err == nil {
+			t.Fatal("expected error from failed write")
+		}
+
+		after, _ := os.ReadFile(m.filePath)
+		if string(after) != string(before) {
+			t.Errorf("expected original content to be kept, got %q", after)
+		}
+
+		assertNoTempFiles(t, filepath.Dir(m.filePath))
+	})
+
+	t.Run("successful save leaves no temporary files", func(t *testing.T) {
+		m, _ := setup(t)
+
+		if err := m.Save(types.NewColleagues()); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+
+		assertNoTempFiles(t, filepath.Dir(m.filePath))
+	})
+
+	t.Run("preserves permissions", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("unix permissions are not supported on windows")
+		}
+
+		m, _ := setup(t)
+		if err := os.Chmod(m.filePath, 0644); err != nil {
+			t.Fatalf("failed to chmod: %v", err)
+		}
+
+		if err := m.Save(types.NewColleagues()); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+
+		info, err := os.Stat(m.filePath)
+		if err != nil {
+			t.Fatalf("failed to stat file: %v", err)
+		}
+
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("expected permission 0600, got %04o", info.Mode().Perm())
+		}
+	})
+}
+
+// isLiveTempFile reports whether f is the temporary file for colleagues.json,
+// as opposed to one for a backup snapshot
+func isLiveTempFile(f *os.File) bool {
+	return strings.HasPrefix(filepath.Base(f.Name()), ".colleagues.json.tmp-")
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.Contains(d.Name(), ".tmp-") {
+			t.Errorf("unexpected file left behind: %s", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+}
+
 func TestManager_Load(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -343,7 +598,21 @@ func TestManager_Load(t *testing.T) {
 			wantErr:     true,
 		},
 		{
-			name:        "malformed JSON - not an array",
+			name:        "versioned envelope",
+			fileContent: `{"version":1,"colleagues":[{"id":"abc123","name":"Alice","city":"London","timezone":"Europe/London"}]}`,
+			setupFile:   true,
+			wantCount:   1,
+			wantErr:     false,
+		},
+		{
+			name:        "versioned envelope without colleagues",
+			fileContent: `{"version":1}`,
+			setupFile:   true,
+			wantCount:   0,
+			wantErr:     false,
+		},
+		{
+			name:        "malformed JSON - object without version",
 			fileContent: `{"name":"Alice"}`,
 			setupFile:   true,
 			wantCount:   0,
@@ -516,3 +785,598 @@ func mustNewColleague(t *testing.T, name, city, tz string) types.Colleague {
 	}
 	return colleague
 }
+
+func TestManager_Load_AssignsMissingIDs(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "colleagues.json")
+	legacy := `[{"name":"Alice","city":"London","timezone":"Europe/London"},{"id":"abc123","name":"Bob","city":"NYC","timezone":"America/New_York"}]`
+	if err := os.WriteFile(testFile, []byte(legacy), 0600); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	m := &Manager{filePath: testFile}
+
+	first, err := m.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if (*first)[0].ID == "" {
+		t.Fatal("expected an id to be assigned")
+	}
+
+	if (*first)[1].ID != "abc123" {
+		t.Errorf("expected existing id to be kept, got %q", (*first)[1].ID)
+	}
+
+	data, _ := os.ReadFile(testFile)
+	if string(data) != legacy {
+		t.Fatalf("expected load to leave the file alone, got %q", data)
+	}
+
+	if err := m.Update(func(cl *types.ColleagueList) error { return nil }); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	second, err := m.Load()
+	if err != nil {
+		t.Fatalf("second load failed: %v", err)
+	}
+
+	third, err := m.Load()
+	if err != nil {
+		t.Fatalf("third load failed: %v", err)
+	}
+
+	if (*second)[0].ID == "" || (*second)[0].ID != (*third)[0].ID {
+		t.Errorf("expected assigned id to be persisted, got %q then %q", (*second)[0].ID, (*third)[0].ID)
+	}
+}
+
+func TestManager_Load_DuplicateIDs(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "colleagues.json")
+	content := `{"version":1,"colleagues":[` +
+		`{"id":"abc123","name":"Alice","city":"London","timezone":"Europe/London"},` +
+		`{"id":"ABC123","name":"Bob","city":"NYC","timezone":"America/New_York"}]}`
+	if err := os.WriteFile(testFile, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	m := &Manager{filePath: testFile}
+	if _, err := m.Load(); !errors.Is(err, types.ErrDuplicateID) {
+		t.Errorf("expected %v, got %v", types.ErrDuplicateID, err)
+	}
+}
+
+func TestManager_Update(t *testing.T) {
+	t.Run("saves changes", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+
+		err := m.Update(func(cl *types.ColleagueList) error {
+			cl.Add(mustNewColleague(t, "Alice", "London", "Europe/London"))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("update failed: %v", err)
+		}
+
+		loaded, _ := m.Load()
+		if len(*loaded) != 1 {
+			t.Errorf("expected 1 colleague, got %d", len(*loaded))
+		}
+	})
+
+	t.Run("does not save when fn fails", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		wantErr := errors.New("boom")
+
+		err := m.Update(func(cl *types.ColleagueList) error {
+			cl.Add(mustNewColleague(t, "Alice", "London", "Europe/London"))
+			return wantErr
+		})
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+
+		if m.Exists() {
+			t.Error("expected no file to be written")
+		}
+	})
+
+	t.Run("times out when lock is held", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json"), lockTimeout: 100 * time.Millisecond}
+
+		unlock, err := acquireLock(m.lockPath(), time.Second)
+		if err != nil {
+			t.Fatalf("failed to take lock: %v", err)
+		}
+		defer unlock()
+
+		err = m.Update(func(cl *types.ColleagueList) error {
+			t.Error("fn must not run without the lock")
+			return nil
+		})
+		if !errors.Is(err, ErrLockTimeout) {
+			t.Errorf("expected %v, got %v", ErrLockTimeout, err)
+		}
+	})
+}
+
+func TestManager_Update_Concurrent(t *testing.T) {
+	const adders = 20
+	filePath := filepath.Join(t.TempDir(), "colleagues.json")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, adders)
+	for i := 0; i < adders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// A manager per goroutine opens its own lock file descriptor,
+			// just like separate processes would
+			m := &Manager{filePath: filePath, lockTimeout: time.Minute}
+			errs <- m.Update(func(cl *types.ColleagueList) error {
+				c, err := types.NewColleague(fmt.Sprintf("Colleague %d", i), "London", "Europe/London")
+				if err != nil {
+					return err
+				}
+				cl.Add(c)
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent update failed: %v", err)
+		}
+	}
+
+	loaded, err := (&Manager{filePath: filePath}).Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if len(*loaded) != adders {
+		t.Errorf("expected %d colleagues, got %d: updates were lost", adders, len(*loaded))
+	}
+}
+
+func TestManager_Load_Schema(t *testing.T) {
+	t.Run("legacy array is upgraded with a backup on the next change", func(t *testing.T) {
+		tempDir := t.TempDir()
+		testFile := filepath.Join(tempDir, "colleagues.json")
+		legacy := `[{"id":"abc123","name":"Alice","city":"London","timezone":"Europe/London"}]`
+		if err := os.WriteFile(testFile, []byte(legacy), 0600); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+
+		m := &Manager{filePath: testFile}
+		cl, err := m.Load()
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+
+		if len(*cl) != 1 || (*cl)[0].ID != "abc123" {
+			t.Fatalf("unexpected colleagues %+v", *cl)
+		}
+
+		if data, _ := os.ReadFile(testFile); string(data) != legacy {
+			t.Fatalf("expected load to leave the file alone, got %q", data)
+		}
+		if _, err := os.Stat(testFile + ".v0.bak"); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected no backup before a change, got %v", err)
+		}
+
+		if err := m.Update(func(cl *types.ColleagueList) error { return nil }); err != nil {
+			t.Fatalf("update failed: %v", err)
+		}
+
+		backup, err := os.ReadFile(testFile + ".v0.bak")
+		if err != nil {
+			t.Fatalf("expected pre-migration backup: %v", err)
+		}
+
+		if string(backup) != legacy {
+			t.Errorf("expected backup to hold the original file, got %q", backup)
+		}
+
+		data, _ := os.ReadFile(testFile)
+		var doc document
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("expected upgraded file to be an envelope: %v", err)
+		}
+
+		if doc.Version != SchemaVersion || len(doc.Colleagues) != 1 {
+			t.Errorf("unexpected upgraded document %+v", doc)
+		}
+	})
+
+	t.Run("current version is not rewritten", func(t *testing.T) {
+		tempDir := t.TempDir()
+		testFile := filepath.Join(tempDir, "colleagues.json")
+		content := `{"version":1,"colleagues":[{"id":"abc123","name":"Alice","city":"London","timezone":"Europe/London"}]}`
+		if err := os.WriteFile(testFile, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+
+		m := &Manager{filePath: testFile}
+		if _, err := m.Load(); err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+
+		data, _ := os.ReadFile(testFile)
+		if string(data) != content {
+			t.Errorf("expected file to be unchanged, got %q", data)
+		}
+
+		if _, err := os.Stat(testFile + ".v1.bak"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected no backup, got %v", err)
+		}
+	})
+
+	t.Run("newer version is refused", func(t *testing.T) {
+		tempDir := t.TempDir()
+		testFile := filepath.Join(tempDir, "colleagues.json")
+		content := `{"version":99,"colleagues":[],"settings":{"theme":"dark"}}`
+		if err := os.WriteFile(testFile, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+
+		m := &Manager{filePath: testFile}
+		if _, err := m.Load(); !errors.Is(err, ErrNewerSchema) {
+			t.Fatalf("expected %v, got %v", ErrNewerSchema, err)
+		}
+
+		err := m.Update(func(cl *types.ColleagueList) error { return nil })
+		if !errors.Is(err, ErrNewerSchema) {
+			t.Fatalf("expected update to be refused with %v, got %v", ErrNewerSchema, err)
+		}
+
+		data, _ := os.ReadFile(testFile)
+		if string(data) != content {
+			t.Errorf("expected file to be untouched, got %q", data)
+		}
+	})
+}
+
+func TestManager_Backups(t *testing.T) {
+	saveNames := func(t *testing.T, m *Manager, names ...string) {
+		t.Helper()
+		for _, name := range names {
+			err := m.Update(func(cl *types.ColleagueList) error {
+				cl.Add(mustNewColleague(t, name, "London", "Europe/London"))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("update failed: %v", err)
+			}
+		}
+	}
+
+	t.Run("snapshots the previous file on every save", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		saveNames(t, m, "Alice", "Bob", "Carla")
+
+		backups, err := m.Backups()
+		if err != nil {
+			t.Fatalf("failed to list backups: %v", err)
+		}
+
+		// The first save had no previous file to back up
+		if len(backups) != 2 {
+			t.Fatalf("expected 2 backups, got %d", len(backups))
+		}
+
+		if !backups[0].CreatedAt.After(backups[1].CreatedAt) {
+			t.Error("expected backups newest first")
+		}
+
+		_, newest, err := m.LoadBackup("1")
+		if err != nil {
+			t.Fatalf("failed to load backup: %v", err)
+		}
+
+		if len(*newest) != 2 {
+			t.Errorf("expected newest backup to hold 2 colleagues, got %d", len(*newest))
+		}
+	})
+
+	t.Run("keeps only the most recent snapshots", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json"), backupLimit: 3}
+		saveNames(t, m, "A", "B", "C", "D", "E", "F")
+
+		backups, err := m.Backups()
+		if err != nil {
+			t.Fatalf("failed to list backups: %v", err)
+		}
+
+		if len(backups) != 3 {
+			t.Fatalf("expected 3 backups, got %d", len(backups))
+		}
+
+		_, oldest, err := m.LoadBackup(backups[2].Name)
+		if err != nil {
+			t.Fatalf("failed to load backup: %v", err)
+		}
+
+		if len(*oldest) != 3 {
+			t.Errorf("expected oldest kept backup to hold 3 colleagues, got %d", len(*oldest))
+		}
+	})
+
+	t.Run("restore replaces the live file and backs it up", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		saveNames(t, m, "Alice", "Bob")
+
+		err := m.Update(func(cl *types.ColleagueList) error {
+			_, err := cl.Remove(1)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("update failed: %v", err)
+		}
+
+		_, restored, err := m.Restore("1")
+		if err != nil {
+			t.Fatalf("restore failed: %v", err)
+		}
+
+		if len(*restored) != 2 {
+			t.Errorf("expected 2 restored colleagues, got %d", len(*restored))
+		}
+
+		loaded, _ := m.Load()
+		if len(*loaded) != 2 {
+			t.Errorf("expected live file to hold 2 colleagues, got %d", len(*loaded))
+		}
+
+		_, previous, err := m.LoadBackup("1")
+		if err != nil {
+			t.Fatalf("failed to load backup: %v", err)
+		}
+
+		if len(*previous) != 1 {
+			t.Errorf("expected the pre-restore file to be backed up, got %d colleagues", len(*previous))
+		}
+	})
+
+	t.Run("invalid snapshot is refused", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		saveNames(t, m, "Alice", "Bob")
+
+		backups, _ := m.Backups()
+		invalid := `{"version":1,"colleagues":[{"id":"abc123","name":"Alice","city":"London","timezone":"Mars/Olympus"}]}`
+		if err := os.WriteFile(backups[0].Path, []byte(invalid), 0600); err != nil {
+			t.Fatalf("failed to corrupt backup: %v", err)
+		}
+
+		if _, _, err := m.Restore(backups[0].Name); err == nil {
+			t.Fatal("expected invalid backup to be refused")
+		}
+
+		loaded, _ := m.Load()
+		if len(*loaded) != 2 {
+			t.Errorf("expected live file to be untouched, got %d colleagues", len(*loaded))
+		}
+	})
+
+	t.Run("unknown snapshot", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+
+		for _, ref := range []string{"1", "colleagues-nope.json"} {
+			if _, _, err := m.Restore(ref); !errors.Is(err, ErrBackupNotFound) {
+				t.Errorf("expected %v for %q, got %v", ErrBackupNotFound, ref, err)
+			}
+		}
+	})
+}
+
+func TestManager_Journal(t *testing.T) {
+	add := func(t *testing.T, m *Manager, name string) types.Colleague {
+		t.Helper()
+		var added types.Colleague
+		err := m.Record(func(cl *types.ColleagueList) (Change, error) {
+			cl.Add(mustNewColleague(t, name, "London", "Europe/London"))
+			added = (*cl)[len(*cl)-1]
+			return Change{Op: OpAdd, Position: len(*cl), After: &added}, nil
+		})
+		if err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+		return added
+	}
+
+	names := func(t *testing.T, m *Manager) string {
+		t.Helper()
+		cl, err := m.Load()
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		var got []string
+		for _, c := range *cl {
+			got = append(got, c.Name)
+		}
+		return strings.Join(got, ",")
+	}
+
+	t.Run("undoes and redoes add, edit and remove", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		add(t, m, "Alice")
+		bob := add(t, m, "Bob")
+		add(t, m, "Carla")
+
+		err := m.Record(func(cl *types.ColleagueList) (Change, error) {
+			edited := bob
+			edited.Name = "Robert"
+			if err := cl.Update(2, edited); err != nil {
+				return Change{}, err
+			}
+			return Change{Op: OpEdit, Position: 2, Before: &bob, After: &edited}, nil
+		})
+		if err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+
+		err = m.Record(func(cl *types.ColleagueList) (Change, error) {
+			removed, err := cl.Remove(1)
+			return Change{Op: OpRemove, Position: 1, Before: &removed}, err
+		})
+		if err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+
+		if got := names(t, m); got != "Robert,Carla" {
+			t.Fatalf("got %q before undo", got)
+		}
+
+		steps := []struct {
+			replay func() (JournalEntry, error)
+			op     string
+			want   string
+		}{
+			{m.Undo, OpRemove, "Alice,Robert,Carla"},
+			{m.Undo, OpEdit, "Alice,Bob,Carla"},
+			{m.Undo, OpAdd, "Alice,Bob"},
+			{m.Redo, OpAdd, "Alice,Bob,Carla"},
+			{m.Redo, OpEdit, "Alice,Robert,Carla"},
+		}
+		for i, step := range steps {
+			entry, err := step.replay()
+			if err != nil {
+				t.Fatalf("step %d: unexpected error: %v", i+1, err)
+			}
+			if entry.Op != step.op {
+				t.Errorf("step %d: replayed %q, want %q", i+1, entry.Op, step.op)
+			}
+			if got := names(t, m); got != step.want {
+				t.Errorf("step %d: got %q, want %q", i+1, got, step.want)
+			}
+		}
+
+		// A new change discards what is left to redo
+		add(t, m, "Dario")
+		if _, err := m.Redo(); !errors.Is(err, ErrNothingToRedo) {
+			t.Errorf("expected %v, got %v", ErrNothingToRedo, err)
+		}
+	})
+
+	t.Run("nothing to undo", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		if _, err := m.Undo(); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("expected %v, got %v", ErrNothingToUndo, err)
+		}
+
+		add(t, m, "Alice")
+		if _, err := m.Undo(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := m.Undo(); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("expected %v, got %v", ErrNothingToUndo, err)
+		}
+	})
+
+	t.Run("keeps the last changes only", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		for i := range journalLimit + 2 {
+			add(t, m, fmt.Sprintf("Colleague %d", i+1))
+		}
+
+		// An undo followed by a new change leaves the undo out of the journal
+		if _, err := m.Undo(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		add(t, m, "Dario")
+
+		for i := range journalLimit {
+			if _, err := m.Undo(); err != nil {
+				t.Fatalf("undo %d: unexpected error: %v", i+1, err)
+			}
+		}
+		if _, err := m.Undo(); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("expected %v, got %v", ErrNothingToUndo, err)
+		}
+		if got := names(t, m); got != "Colleague 1,Colleague 2" {
+			t.Errorf("got %q after undoing everything", got)
+		}
+	})
+
+	t.Run("compacts the journal once in a while", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		for i := range journalCompactAt {
+			add(t, m, fmt.Sprintf("Colleague %d", i+1))
+		}
+
+		entries, err := m.readJournal()
+		if err != nil {
+			t.Fatalf("failed to read journal: %v", err)
+		}
+		if len(entries) != journalCompactAt {
+			t.Fatalf("got %d journal entries before compacting, want %d", len(entries), journalCompactAt)
+		}
+
+		add(t, m, "Dario")
+		if entries, err = m.readJournal(); err != nil {
+			t.Fatalf("failed to read journal: %v", err)
+		}
+		if len(entries) != journalLimit {
+			t.Fatalf("got %d journal entries after compacting, want %d", len(entries), journalLimit)
+		}
+		if last := entries[len(entries)-1]; last.Seq != journalCompactAt+1 || last.Colleague().Name != "Dario" {
+			t.Errorf("got last entry %d for %q", last.Seq, last.Colleague().Name)
+		}
+	})
+
+	t.Run("takes back the entry when the save fails", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		add(t, m, "Alice")
+
+		err := m.Record(func(cl *types.ColleagueList) (Change, error) {
+			cl.Add(mustNewColleague(t, "Bob", "London", "Europe/London"))
+			bob := (*cl)[len(*cl)-1]
+			// a directory in place of the file cannot be replaced
+			if err := os.Remove(m.filePath); err != nil {
+				return Change{}, err
+			}
+			if err := os.Mkdir(m.filePath, 0700); err != nil {
+				return Change{}, err
+			}
+			return Change{Op: OpAdd, Position: len(*cl), After: &bob}, nil
+		})
+		if err == nil {
+			t.Fatal("expected the save to fail")
+		}
+
+		entries, err := m.readJournal()
+		if err != nil {
+			t.Fatalf("failed to read journal: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Colleague().Name != "Alice" {
+			t.Errorf("expected only the entry for Alice, got %+v", entries)
+		}
+	})
+
+	t.Run("refuses when the file has diverged", func(t *testing.T) {
+		m := &Manager{filePath: filepath.Join(t.TempDir(), "colleagues.json")}
+		add(t, m, "Alice")
+
+		// Changes made without the journal are not undone
+		err := m.Update(func(cl *types.ColleagueList) error {
+			cl.Add(mustNewColleague(t, "Bob", "London", "Europe/London"))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("update failed: %v", err)
+		}
+
+		if _, err := m.Undo(); !errors.Is(err, ErrJournalDiverged) {
+			t.Fatalf("expected %v, got %v", ErrJournalDiverged, err)
+		}
+
+		if got := names(t, m); got != "Alice,Bob" {
+			t.Errorf("expected file to be untouched, got %q", got)
+		}
+	})
+}
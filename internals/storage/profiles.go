@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+// DefaultProfile is the profile stored in colleagues.json itself, which is
+// the only one that existed before profiles were introduced
+const DefaultProfile = "default"
+
+const settingsFile = "settings.json"
+
+var (
+	ErrInvalidProfile  = errors.New("profile names must be 1-32 letters, digits, '-' or '_'")
+	ErrProfileExists   = errors.New("profile already exists")
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrProfileInUse    = errors.New("profile is the default profile")
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Profiles manages the named rosters kept in a data directory: the default
+// profile in colleagues.json and every other one in profiles/<name>.json
+type Profiles struct {
+	homeDir string
+	dir     string
+}
+
+// settings holds the choices persisted next to the rosters
+type settings struct {
+	DefaultProfile string `json:"default_profile,omitempty"`
+}
+
+// NewProfiles returns the profiles kept in dir. homeDir is only used to
+// display paths and may be empty
+func NewProfiles(homeDir, dir string) *Profiles {
+	return &Profiles{homeDir: homeDir, dir: dir}
+}
+
+// NormalizeProfile lowercases name and checks it can be used as a file name
+func NormalizeProfile(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !profileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidProfile, name)
+	}
+	return name, nil
+}
+
+// Path returns the roster file of the profile name, which may not exist yet
+func (p *Profiles) Path(name string) (string, error) {
+	name, err := NormalizeProfile(name)
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultProfile {
+		return filepath.Join(p.dir, fileName), nil
+	}
+	return filepath.Join(p.dir, "profiles", name+".json"), nil
+}
+
+// Manager returns a manager for the roster of the profile name
+func (p *Profiles) Manager(name string) (*Manager, error) {
+	path, err := p.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewManagerForFile(p.homeDir, path)
+}
+
+// Exists reports whether the roster of the profile name has been created
+func (p *Profiles) Exists(name string) bool {
+	path, err := p.Path(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// List returns the names of the existing profiles, sorted
+func (p *Profiles) List() ([]string, error) {
+	var names []string
+	if p.Exists(DefaultProfile) {
+		names = append(names, DefaultProfile)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(p.dir, "profiles"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok || !profileNamePattern.MatchString(name) || name == DefaultProfile {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
+
+// Create writes an empty roster for the profile name
+func (p *Profiles) Create(name string) (*Manager, error) {
+	m, err := p.Manager(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Exists() {
+		return nil, fmt.Errorf("%w: %s", ErrProfileExists, name)
+	}
+
+	if err := m.EnsureFolder(); err != nil {
+		return nil, err
+	}
+
+	if err := m.Save(types.NewColleagues()); err != nil {
+		return nil, fmt.Errorf("failed to create profile %s: %w", name, err)
+	}
+	return m, nil
+}
+
+// Delete removes the roster of the profile name and its journal. A last
+// snapshot is kept with its backups, so that it can still be recovered. The
+// default profile cannot be deleted
+func (p *Profiles) Delete(name string) error {
+	name, err := NormalizeProfile(name)
+	if err != nil {
+		return err
+	}
+
+	current, err := p.Default()
+	if err != nil {
+		return err
+	}
+	if name == current {
+		return fmt.Errorf("%w: %s", ErrProfileInUse, name)
+	}
+
+	m, err := p.Manager(name)
+	if err != nil {
+		return err
+	}
+
+	if !m.Exists() {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+
+	unlock, err := m.lock()
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		if err := m.snapshot(); err != nil {
+			return fmt.Errorf("failed to back up profile %s: %w", name, err)
+		}
+		for _, path := range []string{m.filePath, m.journalPath()} {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to delete profile %s: %w", name, err)
+			}
+		}
+		return nil
+	}()
+	unlock()
+	if err != nil {
+		return err
+	}
+
+	// The lock file goes last, once released, as Windows cannot remove a
+	// file that is still open
+	if err := os.Remove(m.lockPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// Default returns the profile used when none is given, DefaultProfile unless
+// another one was chosen with SetDefault
+func (p *Profiles) Default() (string, error) {
+	s, err := p.readSettings()
+	if err != nil {
+		return "", err
+	}
+	if s.DefaultProfile == "" {
+		return DefaultProfile, nil
+	}
+	return s.DefaultProfile, nil
+}
+
+// SetDefault persists the profile name as the one used when none is given
+func (p *Profiles) SetDefault(name string) error {
+	name, err := NormalizeProfile(name)
+	if err != nil {
+		return err
+	}
+
+	if !p.Exists(name) {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+
+	s, err := p.readSettings()
+	if err != nil {
+		return err
+	}
+	s.DefaultProfile = name
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", p.dir, err)
+	}
+	return WriteFileAtomic(filepath.Join(p.dir, settingsFile), data, 0600)
+}
+
+func (p *Profiles) readSettings() (settings, error) {
+	var s settings
+	data, err := os.ReadFile(filepath.Join(p.dir, settingsFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return s, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+func TestNormalizeProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "lowercase", input: "work", want: "work"},
+		{name: "mixed case and spaces", input: " On-Call ", want: "on-call"},
+		{name: "digits and underscore", input: "team_2", want: "team_2"},
+		{name: "empty", input: "", wantErr: ErrInvalidProfile},
+		{name: "path separator", input: "../work", wantErr: ErrInvalidProfile},
+		{name: "space inside", input: "my team", wantErr: ErrInvalidProfile},
+		{name: "too long", input: strings.Repeat("a", 33), wantErr: ErrInvalidProfile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeProfile(tt.input)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	t.Run("default profile is colleagues.json", func(t *testing.T) {
+		dir := t.TempDir()
+		p := NewProfiles("", dir)
+
+		path, err := p.Path(DefaultProfile)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if path != filepath.Join(dir, "colleagues.json") {
+			t.Errorf("got %q", path)
+		}
+
+		path, err = p.Path("Work")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if path != filepath.Join(dir, "profiles", "work.json") {
+			t.Errorf("got %q", path)
+		}
+
+		got, err := p.Default()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != DefaultProfile {
+			t.Errorf("got default %q, want %q", got, DefaultProfile)
+		}
+	})
+
+	t.Run("create, list, use and delete", func(t *testing.T) {
+		p := NewProfiles("", t.TempDir())
+
+		for _, name := range []string{DefaultProfile, "work", "oncall"} {
+			if _, err := p.Create(name); err != nil {
+				t.Fatalf("failed to create %s: %v", name, err)
+			}
+		}
+
+		if _, err := p.Create("work"); !errors.Is(err, ErrProfileExists) {
+			t.Errorf("expected %v, got %v", ErrProfileExists, err)
+		}
+
+		names, err := p.List()
+		if err != nil {
+			t.Fatalf("failed to list profiles: %v", err)
+		}
+		if got := strings.Join(names, ","); got != "default,oncall,work" {
+			t.Errorf("got %q", got)
+		}
+
+		if err := p.SetDefault("work"); err != nil {
+			t.Fatalf("failed to set default: %v", err)
+		}
+		if got, _ := p.Default(); got != "work" {
+			t.Errorf("got default %q, want work", got)
+		}
+
+		if err := p.SetDefault("missing"); !errors.Is(err, ErrProfileNotFound) {
+			t.Errorf("expected %v, got %v", ErrProfileNotFound, err)
+		}
+
+		if err := p.Delete("work"); !errors.Is(err, ErrProfileInUse) {
+			t.Errorf("expected %v, got %v", ErrProfileInUse, err)
+		}
+
+		m, err := p.Manager("oncall")
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+		err = m.Update(func(cl *types.ColleagueList) error {
+			cl.Add(mustNewColleague(t, "Alice", "London", "Europe/London"))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("update failed: %v", err)
+		}
+
+		if err := p.Delete("oncall"); err != nil {
+			t.Fatalf("failed to delete: %v", err)
+		}
+		if _, err := os.Stat(m.GetFilePath()); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected roster to be deleted, got %v", err)
+		}
+		for _, path := range []string{m.lockPath(), m.journalPath()} {
+			if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected %s to be deleted, got %v", filepath.Base(path), err)
+			}
+		}
+
+		// The deleted roster can still be recovered from its backups
+		_, last, err := m.LoadBackup("1")
+		if err != nil {
+			t.Fatalf("failed to load backup: %v", err)
+		}
+		if len(*last) != 1 {
+			t.Errorf("expected last backup to hold 1 colleague, got %d", len(*last))
+		}
+
+		if err := p.Delete("oncall"); !errors.Is(err, ErrProfileNotFound) {
+			t.Errorf("expected %v, got %v", ErrProfileNotFound, err)
+		}
+	})
+}
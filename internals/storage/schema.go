@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matteo-gildone/teamtime/internals/types"
+)
+
+// SchemaVersion is the version of the colleagues file written by this build
+const SchemaVersion = 1
+
+var (
+	ErrNewerSchema   = errors.New("colleagues file was written by a newer version of teamtime")
+	ErrMissingSchema = errors.New("colleagues file has no schema version")
+)
+
+// document is the on-disk format of the colleagues file
+type document struct {
+	Version    int                 `json:"version"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	Colleagues types.ColleagueList `json:"colleagues"`
+}
+
+// migrations[i] upgrades raw file contents from schema version i to i+1
+var migrations = []func(data []byte) ([]byte, error){
+	migrateV0,
+}
+
+// migrateV0 wraps the legacy bare array of colleagues in a versioned envelope
+func migrateV0(data []byte) ([]byte, error) {
+	var colleagues []json.RawMessage
+	if err := json.Unmarshal(data, &colleagues); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		Version    int               `json:"version"`
+		Colleagues []json.RawMessage `json:"colleagues"`
+	}{
+		Version:    1,
+		Colleagues: colleagues,
+	})
+}
+
+// schemaVersion reports the schema version of raw file contents. Legacy files
+// holding a bare JSON array are version 0
+func schemaVersion(data []byte) (int, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return 0, nil
+	}
+
+	var header struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, err
+	}
+
+	if header.Version == nil || *header.Version < 1 {
+		return 0, ErrMissingSchema
+	}
+	return *header.Version, nil
+}
+
+// decodeDocument parses raw file contents, upgrading older schema versions.
+// It returns the version the data was read from
+func decodeDocument(data []byte) (*document, int, error) {
+	version, err := schemaVersion(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if version > SchemaVersion {
+		return nil, version, fmt.Errorf("%w (file version %d, supported up to %d), please upgrade teamtime",
+			ErrNewerSchema, version, SchemaVersion)
+	}
+
+	upgraded := data
+	for v := version; v < SchemaVersion; v++ {
+		upgraded, err = migrations[v](upgraded)
+		if err != nil {
+			return nil, version, fmt.Errorf("failed to migrate from version %d: %w", v, err)
+		}
+	}
+
+	var doc document
+	if err := json.Unmarshal(upgraded, &doc); err != nil {
+		return nil, version, err
+	}
+
+	if doc.Colleagues == nil {
+		doc.Colleagues = types.ColleagueList{}
+	}
+	return &doc, version, nil
+}
+
+func encodeDocument(cl *types.ColleagueList) ([]byte, error) {
+	colleagues := *cl
+	if colleagues == nil {
+		colleagues = types.ColleagueList{}
+	}
+
+	return json.MarshalIndent(document{
+		Version:    SchemaVersion,
+		UpdatedAt:  time.Now().UTC(),
+		Colleagues: colleagues,
+	}, "", "  ")
+}
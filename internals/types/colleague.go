@@ -1,37 +1,188 @@
 package types
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
 var (
+	ErrMissingID       = errors.New("'ID' must not be empty")
 	ErrMissingName     = errors.New("'Name' must not be empty")
 	ErrMissingCity     = errors.New("'City' must not be empty")
 	ErrMissingTimezone = errors.New("'Timezone' must not be empty")
 	ErrorInvalidIndex  = errors.New("invalid index")
 	ErrEmptyList       = errors.New("colleagues list is empty")
+	ErrLongID          = errors.New("id is too long")
 	ErrLongName        = errors.New("name is too long")
 	ErrLongCity        = errors.New("city is too long")
 	ErrLongTimezone    = errors.New("timezone is too long")
+	ErrInvalidHours    = errors.New("invalid hours")
+	ErrExtendedHours   = errors.New("extended hours must include work hours")
+	ErrInvalidWorkDays = errors.New("invalid work days")
+	ErrNotFound        = errors.New("colleague not found")
+	ErrAmbiguousName   = errors.New("more than one colleague with this name")
+	ErrEmptyPatch      = errors.New("nothing to update")
+	ErrDuplicateID     = errors.New("id already used by another colleague")
 )
 
 const (
+	idLength          = 6
+	idMaxLength       = 16
 	nameMaxLength     = 50
 	cityMaxLength     = 50
 	timezoneMaxLength = 50
 )
 
+var (
+	DefaultWorkHours     = Hours{Start: 9, End: 17}
+	DefaultExtendedHours = Hours{Start: 7, End: 20}
+	DefaultWorkDays      = Weekdays{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+)
+
+// Hours is a range of hours of the day, from Start (inclusive) to End (exclusive)
+type Hours struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+func (h Hours) Validate() error {
+	if h.Start < 0 || h.End > 24 || h.Start >= h.End {
+		return fmt.Errorf("%w: %s (must be between 0 and 24, start before end)", ErrInvalidHours, h)
+	}
+	return nil
+}
+
+// Contains reports whether hour falls within the range
+func (h Hours) Contains(hour int) bool {
+	return hour >= h.Start && hour < h.End
+}
+
+// Includes reports whether other is entirely within the range
+func (h Hours) Includes(other Hours) bool {
+	return other.Start >= h.Start && other.End <= h.End
+}
+
+func (h Hours) String() string {
+	return fmt.Sprintf("%d-%d", h.Start, h.End)
+}
+
+// ParseHours parses a range such as "9-17" or "09:00-17:00"
+func ParseHours(s string) (Hours, error) {
+	start, end, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return Hours{}, fmt.Errorf("%w: %q (expected format 9-17)", ErrInvalidHours, s)
+	}
+
+	startHour, err := parseHour(start)
+	if err != nil {
+		return Hours{}, fmt.Errorf("%w: %q (expected format 9-17)", ErrInvalidHours, s)
+	}
+
+	endHour, err := parseHour(end)
+	if err != nil {
+		return Hours{}, fmt.Errorf("%w: %q (expected format 9-17)", ErrInvalidHours, s)
+	}
+
+	h := Hours{Start: startHour, End: endHour}
+	if err := h.Validate(); err != nil {
+		return Hours{}, err
+	}
+	return h, nil
+}
+
+func parseHour(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, ":00")
+	return strconv.Atoi(s)
+}
+
 type Colleague struct {
-	Name     string `json:"name"`
-	City     string `json:"city"`
-	Timezone string `json:"timezone"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	City          string   `json:"city"`
+	Timezone      string   `json:"timezone"`
+	WorkHours     *Hours   `json:"work_hours,omitempty"`
+	ExtendedHours *Hours   `json:"extended_hours,omitempty"`
+	WorkDays      Weekdays `json:"work_days,omitempty"`
+	Tags          Tags     `json:"tags,omitempty"`
+}
+
+// Option customises a colleague created with NewColleague
+type Option func(*Colleague)
+
+// WithWorkHours sets the colleague's working hours
+func WithWorkHours(h Hours) Option {
+	return func(c *Colleague) {
+		c.WorkHours = &h
+	}
+}
+
+// WithExtendedHours sets the colleague's extended hours
+func WithExtendedHours(h Hours) Option {
+	return func(c *Colleague) {
+		c.ExtendedHours = &h
+	}
+}
+
+// WithWorkDays sets the days of the week the colleague works
+func WithWorkDays(d Weekdays) Option {
+	return func(c *Colleague) {
+		c.WorkDays = d
+	}
+}
+
+// WithTags sets the colleague's tags
+func WithTags(t Tags) Option {
+	return func(c *Colleague) {
+		c.Tags = t.With(nil)
+	}
+}
+
+// EffectiveWorkDays returns the colleague's working days, or the default when unset
+func (c Colleague) EffectiveWorkDays() Weekdays {
+	if len(c.WorkDays) > 0 {
+		return c.WorkDays
+	}
+	return DefaultWorkDays
+}
+
+// EffectiveWorkHours returns the colleague's working hours, or the default when unset
+func (c Colleague) EffectiveWorkHours() Hours {
+	if c.WorkHours != nil {
+		return *c.WorkHours
+	}
+	return DefaultWorkHours
+}
+
+// EffectiveExtendedHours returns the colleague's extended hours. When unset the
+// default is used, widened if needed to include the colleague's working hours
+func (c Colleague) EffectiveExtendedHours() Hours {
+	if c.ExtendedHours != nil {
+		return *c.ExtendedHours
+	}
+	work := c.EffectiveWorkHours()
+	return Hours{
+		Start: min(DefaultExtendedHours.Start, work.Start),
+		End:   max(DefaultExtendedHours.End, work.End),
+	}
 }
 
 func (c Colleague) Validate() error {
 
+	if c.ID == "" {
+		return ErrMissingID
+	}
+
+	if len(c.ID) > idMaxLength {
+		return fmt.Errorf("%w (max %d characters)", ErrLongID, idMaxLength)
+	}
+
 	if c.Name == "" {
 		return ErrMissingName
 	}
@@ -60,19 +211,52 @@ func (c Colleague) Validate() error {
 		return err
 	}
 
+	if c.WorkHours != nil {
+		if err := c.WorkHours.Validate(); err != nil {
+			return fmt.Errorf("work hours: %w", err)
+		}
+	}
+
+	if c.ExtendedHours != nil {
+		if err := c.ExtendedHours.Validate(); err != nil {
+			return fmt.Errorf("extended hours: %w", err)
+		}
+	}
+
+	if err := c.WorkDays.Validate(); err != nil {
+		return fmt.Errorf("work days: %w", err)
+	}
+
+	if !c.EffectiveExtendedHours().Includes(c.EffectiveWorkHours()) {
+		return fmt.Errorf("%w: extended %s, work %s", ErrExtendedHours, c.EffectiveExtendedHours(), c.EffectiveWorkHours())
+	}
+
+	if err := c.Tags.Validate(); err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+
 	return nil
 }
 
-func NewColleague(name, city, tz string) (Colleague, error) {
+func NewColleague(name, city, tz string, opts ...Option) (Colleague, error) {
 	name = strings.TrimSpace(name)
 	city = strings.TrimSpace(city)
 	tz = strings.TrimSpace(tz)
+	id, err := NewID()
+	if err != nil {
+		return Colleague{}, err
+	}
 	newColleague := Colleague{
+		ID:       id,
 		Name:     name,
 		City:     city,
 		Timezone: tz,
 	}
 
+	for _, opt := range opts {
+		opt(&newColleague)
+	}
+
 	if err := newColleague.Validate(); err != nil {
 		return Colleague{}, err
 	}
@@ -80,10 +264,79 @@ func NewColleague(name, city, tz string) (Colleague, error) {
 	return newColleague, nil
 }
 
+// NewID returns a short random identifier for a colleague
+func NewID() (string, error) {
+	b := make([]byte, idLength/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
 type ColleagueList []Colleague
 
-func (cl *ColleagueList) Add(newColleague Colleague) {
+// Add appends a colleague, giving it a new ID if it is missing or already taken
+func (cl *ColleagueList) Add(newColleague Colleague) error {
+	for newColleague.ID == "" || cl.HasID(newColleague.ID) {
+		id, err := NewID()
+		if err != nil {
+			return err
+		}
+		newColleague.ID = id
+	}
 	*cl = append(*cl, newColleague)
+	return nil
+}
+
+// AssignMissingIDs gives an ID to every colleague without one and reports
+// whether any colleague was changed
+func (cl ColleagueList) AssignMissingIDs() (bool, error) {
+	changed := false
+	for i := range cl {
+		if cl[i].ID != "" {
+			continue
+		}
+		for cl[i].ID == "" || cl.countID(cl[i].ID) > 1 {
+			id, err := NewID()
+			if err != nil {
+				return changed, err
+			}
+			cl[i].ID = id
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// Validate checks every colleague and that no two of them share an ID
+func (cl ColleagueList) Validate() error {
+	seen := make(map[string]int, len(cl))
+	for i, c := range cl {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("colleague at index %d: %w", i+1, err)
+		}
+		id := strings.ToLower(c.ID)
+		if first, ok := seen[id]; ok {
+			return fmt.Errorf("colleague at index %d: %w: %q, as at index %d", i+1, ErrDuplicateID, c.ID, first)
+		}
+		seen[id] = i + 1
+	}
+	return nil
+}
+
+// HasID reports whether a colleague uses id, ignoring case
+func (cl ColleagueList) HasID(id string) bool {
+	return cl.countID(id) > 0
+}
+
+func (cl ColleagueList) countID(id string) int {
+	n := 0
+	for _, c := range cl {
+		if strings.EqualFold(c.ID, id) {
+			n++
+		}
+	}
+	return n
 }
 
 func (cl *ColleagueList) Remove(idx int) (Colleague, error) {
@@ -102,6 +355,61 @@ func (cl *ColleagueList) Remove(idx int) (Colleague, error) {
 	return deleted, nil
 }
 
+// Insert places c at the 1-based position idx, shifting later colleagues down.
+// An idx one past the end appends
+func (cl *ColleagueList) Insert(idx int, c Colleague) error {
+	if idx <= 0 || idx > len(*cl)+1 {
+		return fmt.Errorf("%w: %d (must be a number between 1 and %d)", ErrorInvalidIndex, idx, len(*cl)+1)
+	}
+
+	*cl = slices.Insert(*cl, idx-1, c)
+	return nil
+}
+
+// Update replaces the colleague at the 1-based position idx
+func (cl *ColleagueList) Update(idx int, c Colleague) error {
+	if len(*cl) == 0 {
+		return ErrEmptyList
+	}
+
+	if idx <= 0 || idx > len(*cl) {
+		return fmt.Errorf("%w: %d (must be a number between 1 and %d)", ErrorInvalidIndex, idx, len(*cl))
+	}
+
+	(*cl)[idx-1] = c
+	return nil
+}
+
+// IndexOf returns the 1-based position of the colleague referenced by ref,
+// which is either an ID or an exact name, both case-insensitive
+func (cl ColleagueList) IndexOf(ref string) (int, error) {
+	if len(cl) == 0 {
+		return 0, ErrEmptyList
+	}
+
+	ref = strings.TrimSpace(ref)
+	for i, c := range cl {
+		if strings.EqualFold(c.ID, ref) {
+			return i + 1, nil
+		}
+	}
+
+	found := 0
+	for i, c := range cl {
+		if strings.EqualFold(c.Name, ref) {
+			if found != 0 {
+				return 0, fmt.Errorf("%w: %q, use its ID instead", ErrAmbiguousName, ref)
+			}
+			found = i + 1
+		}
+	}
+
+	if found == 0 {
+		return 0, fmt.Errorf("%w: %q", ErrNotFound, ref)
+	}
+	return found, nil
+}
+
 func NewColleagues() *ColleagueList {
 	return &ColleagueList{}
 }
@@ -1,8 +1,11 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestColleagueList_Add(t *testing.T) {
@@ -362,3 +365,454 @@ func TestNewColleagues(t *testing.T) {
 		t.Errorf("expected empty list, got length %d", len(*cl))
 	}
 }
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Hours
+		wantErr bool
+	}{
+		{name: "simple range", input: "9-17", want: Hours{Start: 9, End: 17}},
+		{name: "with minutes", input: "08:00-16:00", want: Hours{Start: 8, End: 16}},
+		{name: "with spaces", input: " 12 - 20 ", want: Hours{Start: 12, End: 20}},
+		{name: "whole day", input: "0-24", want: Hours{Start: 0, End: 24}},
+		{name: "missing separator", input: "917", wantErr: true},
+		{name: "not a number", input: "nine-17", wantErr: true},
+		{name: "end before start", input: "17-9", wantErr: true},
+		{name: "out of range", input: "9-25", wantErr: true},
+		{name: "empty range", input: "9-9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHours(tt.input)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidHours) {
+					t.Fatalf("expected %v, got %v", ErrInvalidHours, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColleague_NewColleague_Hours(t *testing.T) {
+	t.Run("defaults when unset", func(t *testing.T) {
+		c, err := NewColleague("Alice", "London", "Europe/London")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if c.EffectiveWorkHours() != DefaultWorkHours {
+			t.Errorf("got %v, want %v", c.EffectiveWorkHours(), DefaultWorkHours)
+		}
+
+		if c.EffectiveExtendedHours() != DefaultExtendedHours {
+			t.Errorf("got %v, want %v", c.EffectiveExtendedHours(), DefaultExtendedHours)
+		}
+	})
+
+	t.Run("custom work hours", func(t *testing.T) {
+		work := Hours{Start: 12, End: 20}
+		c, err := NewColleague("Alice", "London", "Europe/London", WithWorkHours(work))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if c.EffectiveWorkHours() != work {
+			t.Errorf("got %v, want %v", c.EffectiveWorkHours(), work)
+		}
+	})
+
+	t.Run("default extended hours widen to include work hours", func(t *testing.T) {
+		c, err := NewColleague("Alice", "London", "Europe/London", WithWorkHours(Hours{Start: 5, End: 13}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := Hours{Start: 5, End: 20}
+		if c.EffectiveExtendedHours() != want {
+			t.Errorf("got %v, want %v", c.EffectiveExtendedHours(), want)
+		}
+	})
+
+	t.Run("extended hours must include work hours", func(t *testing.T) {
+		_, err := NewColleague("Alice", "London", "Europe/London",
+			WithWorkHours(Hours{Start: 8, End: 16}),
+			WithExtendedHours(Hours{Start: 9, End: 20}))
+
+		if !errors.Is(err, ErrExtendedHours) {
+			t.Errorf("expected %v, got %v", ErrExtendedHours, err)
+		}
+	})
+
+	t.Run("invalid work hours", func(t *testing.T) {
+		_, err := NewColleague("Alice", "London", "Europe/London", WithWorkHours(Hours{Start: 18, End: 9}))
+
+		if !errors.Is(err, ErrInvalidHours) {
+			t.Errorf("expected %v, got %v", ErrInvalidHours, err)
+		}
+	})
+}
+
+func TestParseWeekdays(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Weekdays
+		wantErr bool
+	}{
+		{
+			name:  "monday to friday",
+			input: "mon-fri",
+			want:  Weekdays{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		},
+		{
+			name:  "sunday to thursday",
+			input: "sun-thu",
+			want:  Weekdays{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday},
+		},
+		{
+			name:  "range wrapping the week",
+			input: "sat-mon",
+			want:  Weekdays{time.Sunday, time.Monday, time.Saturday},
+		},
+		{
+			name:  "list and range mixed case",
+			input: "Mon,Wed-Thu",
+			want:  Weekdays{time.Monday, time.Wednesday, time.Thursday},
+		},
+		{
+			name:  "duplicates collapse",
+			input: "mon,mon-tue",
+			want:  Weekdays{time.Monday, time.Tuesday},
+		},
+		{name: "unknown day", input: "mon-fry", wantErr: true},
+		{name: "empty", input: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWeekdays(tt.input)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidWorkDays) {
+					t.Fatalf("expected %v, got %v", ErrInvalidWorkDays, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.String() != tt.want.String() {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeekdays_JSON(t *testing.T) {
+	c, err := NewColleague("Alice", "Dubai", "Asia/Dubai", WithWorkDays(Weekdays{time.Monday, time.Friday}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	if !strings.Contains(string(data), `"work_days":["mon","fri"]`) {
+		t.Errorf("expected work days as short names, got %s", data)
+	}
+
+	var loaded Colleague
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if !loaded.EffectiveWorkDays().Contains(time.Friday) || loaded.EffectiveWorkDays().Contains(time.Tuesday) {
+		t.Errorf("got %v, want mon,fri", loaded.WorkDays)
+	}
+
+	if err := json.Unmarshal([]byte(`{"work_days":["someday"]}`), &loaded); !errors.Is(err, ErrInvalidWorkDays) {
+		t.Errorf("expected %v, got %v", ErrInvalidWorkDays, err)
+	}
+}
+
+func TestColleagueList_IndexOf(t *testing.T) {
+	cl := ColleagueList{
+		{ID: "a1b2c3", Name: "Alice", City: "London", Timezone: "Europe/London"},
+		{ID: "d4e5f6", Name: "Bob", City: "NYC", Timezone: "America/New_York"},
+		{ID: "0a0b0c", Name: "bob", City: "Boston", Timezone: "America/New_York"},
+		{ID: "112233", Name: "Alice Jr", City: "London", Timezone: "Europe/London"},
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    int
+		wantErr error
+	}{
+		{name: "by id", ref: "d4e5f6", want: 2},
+		{name: "by id case insensitive", ref: "0A0B0C", want: 3},
+		{name: "by name case insensitive", ref: "alice", want: 1},
+		{name: "positions are not ids", ref: "2", wantErr: ErrNotFound},
+		{name: "unknown name", ref: "Zoe", wantErr: ErrNotFound},
+		{name: "ambiguous name", ref: "Bob", wantErr: ErrAmbiguousName},
+	}
+
+	if _, err := (ColleagueList{}).IndexOf("Alice"); !errors.Is(err, ErrEmptyList) {
+		t.Errorf("expected %v, got %v", ErrEmptyList, err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cl.IndexOf(tt.ref)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColleagueList_Insert(t *testing.T) {
+	tests := []struct {
+		name    string
+		idx     int
+		want    []string
+		wantErr error
+	}{
+		{name: "first", idx: 1, want: []string{"Zoe", "Alice", "Bob"}},
+		{name: "middle", idx: 2, want: []string{"Alice", "Zoe", "Bob"}},
+		{name: "append", idx: 3, want: []string{"Alice", "Bob", "Zoe"}},
+		{name: "zero", idx: 0, wantErr: ErrorInvalidIndex},
+		{name: "past the end", idx: 4, wantErr: ErrorInvalidIndex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := ColleagueList{
+				{ID: "a1b2c3", Name: "Alice", City: "London", Timezone: "Europe/London"},
+				{ID: "d4e5f6", Name: "Bob", City: "NYC", Timezone: "America/New_York"},
+			}
+
+			err := cl.Insert(tt.idx, Colleague{ID: "112233", Name: "Zoe", City: "Rome", Timezone: "Europe/Rome"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, c := range cl {
+				got = append(got, c.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColleaguePatch_Apply(t *testing.T) {
+	original := Colleague{Name: "Alice", City: "London", Timezone: "Europe/London"}
+	city := " Leeds "
+	work := Hours{Start: 8, End: 16}
+
+	patched := ColleaguePatch{City: &city, WorkHours: &work}.Apply(original)
+
+	if patched.City != "Leeds" {
+		t.Errorf("got %q, want %q", patched.City, "Leeds")
+	}
+
+	if patched.Name != "Alice" || patched.Timezone != "Europe/London" {
+		t.Errorf("expected untouched fields to be kept, got %+v", patched)
+	}
+
+	if patched.EffectiveWorkHours() != work {
+		t.Errorf("got %v, want %v", patched.EffectiveWorkHours(), work)
+	}
+
+	if original.City != "London" || original.WorkHours != nil {
+		t.Errorf("expected original to be unchanged, got %+v", original)
+	}
+
+	if !(ColleaguePatch{}).IsEmpty() {
+		t.Error("expected zero patch to be empty")
+	}
+
+	tagged := Colleague{Name: "Bob", Tags: Tags{"backend", "oncall"}}
+	retagged := ColleaguePatch{AddTags: Tags{"lead"}, RemoveTags: Tags{"oncall"}}.Apply(tagged)
+
+	if retagged.Tags.String() != "backend,lead" {
+		t.Errorf("got tags %q, want %q", retagged.Tags, "backend,lead")
+	}
+
+	if tagged.Tags.String() != "backend,oncall" {
+		t.Errorf("expected original tags to be unchanged, got %q", tagged.Tags)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    string
+		wantErr error
+	}{
+		{name: "single", input: []string{"backend"}, want: "backend"},
+		{name: "repeated values", input: []string{"oncall", "backend"}, want: "backend,oncall"},
+		{name: "comma separated", input: []string{"Backend, OnCall"}, want: "backend,oncall"},
+		{name: "duplicates", input: []string{"backend", "backend,BACKEND"}, want: "backend"},
+		{name: "empty", input: []string{"", " , "}, want: ""},
+		{name: "punctuation", input: []string{"squad-a", "team_b", "v1.2"}, want: "squad-a,team_b,v1.2"},
+		{name: "space inside", input: []string{"on call"}, wantErr: ErrInvalidTag},
+		{name: "leading dash", input: []string{"-backend"}, wantErr: ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTags(tt.input...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if tags, _ := ParseTags(""); tags != nil {
+		t.Errorf("expected no tags to be nil, got %#v", tags)
+	}
+}
+
+func TestTags_Match(t *testing.T) {
+	tags := Tags{"backend", "oncall"}
+
+	tests := []struct {
+		name     string
+		want     Tags
+		matchAny bool
+		expected bool
+	}{
+		{name: "no filter", want: nil, expected: true},
+		{name: "all present", want: Tags{"backend", "oncall"}, expected: true},
+		{name: "one missing", want: Tags{"backend", "frontend"}, expected: false},
+		{name: "any with one present", want: Tags{"backend", "frontend"}, matchAny: true, expected: true},
+		{name: "any with none present", want: Tags{"frontend", "lead"}, matchAny: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tags.Match(tt.want, tt.matchAny); got != tt.expected {
+				t.Errorf("got %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestColleague_IDs(t *testing.T) {
+	t.Run("new colleagues get unique ids", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for i := 0; i < 100; i++ {
+			c, err := NewColleague("Alice", "London", "Europe/London")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(c.ID) != idLength {
+				t.Fatalf("got id %q, want %d characters", c.ID, idLength)
+			}
+
+			if seen[c.ID] {
+				t.Fatalf("duplicate id %q", c.ID)
+			}
+			seen[c.ID] = true
+		}
+	})
+
+	t.Run("missing id fails validation", func(t *testing.T) {
+		c := Colleague{Name: "Alice", City: "London", Timezone: "Europe/London"}
+		if err := c.Validate(); !errors.Is(err, ErrMissingID) {
+			t.Errorf("expected %v, got %v", ErrMissingID, err)
+		}
+	})
+
+	t.Run("add replaces duplicate id", func(t *testing.T) {
+		cl := ColleagueList{}
+		cl.Add(Colleague{ID: "abc123", Name: "Alice"})
+		cl.Add(Colleague{ID: "ABC123", Name: "Bob"})
+
+		if cl[1].ID == "" || strings.EqualFold(cl[1].ID, "abc123") {
+			t.Errorf("expected a new id for the duplicate, got %q", cl[1].ID)
+		}
+	})
+
+	t.Run("assign missing ids", func(t *testing.T) {
+		cl := ColleagueList{{ID: "abc123", Name: "Alice"}, {Name: "Bob"}}
+
+		if changed, err := cl.AssignMissingIDs(); err != nil || !changed {
+			t.Errorf("expected list to change, got %v, %v", changed, err)
+		}
+
+		if cl[0].ID != "abc123" || cl[1].ID == "" {
+			t.Errorf("got ids %q and %q", cl[0].ID, cl[1].ID)
+		}
+
+		if changed, _ := cl.AssignMissingIDs(); changed {
+			t.Error("expected no change once every colleague has an id")
+		}
+	})
+
+	t.Run("list validation rejects duplicate ids", func(t *testing.T) {
+		cl := ColleagueList{
+			{ID: "abc123", Name: "Alice", City: "London", Timezone: "Europe/London"},
+			{ID: "ABC123", Name: "Bob", City: "NYC", Timezone: "America/New_York"},
+		}
+		if err := cl.Validate(); !errors.Is(err, ErrDuplicateID) {
+			t.Errorf("expected %v, got %v", ErrDuplicateID, err)
+		}
+
+		cl[1].ID = "def456"
+		if err := cl.Validate(); err != nil {
+			t.Errorf("expected distinct ids to be valid, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package types
+
+import "strings"
+
+// ColleaguePatch describes changes to a colleague. Nil fields are left untouched
+type ColleaguePatch struct {
+	Name          *string
+	City          *string
+	Timezone      *string
+	WorkHours     *Hours
+	ExtendedHours *Hours
+	WorkDays      Weekdays
+	// AddTags and RemoveTags are added to and removed from the existing tags
+	AddTags    Tags
+	RemoveTags Tags
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p ColleaguePatch) IsEmpty() bool {
+	return p.Name == nil &&
+		p.City == nil &&
+		p.Timezone == nil &&
+		p.WorkHours == nil &&
+		p.ExtendedHours == nil &&
+		p.WorkDays == nil &&
+		len(p.AddTags) == 0 &&
+		len(p.RemoveTags) == 0
+}
+
+// Apply returns a copy of c with the patch applied. The result is not validated
+func (p ColleaguePatch) Apply(c Colleague) Colleague {
+	if p.Name != nil {
+		c.Name = strings.TrimSpace(*p.Name)
+	}
+
+	if p.City != nil {
+		c.City = strings.TrimSpace(*p.City)
+	}
+
+	if p.Timezone != nil {
+		c.Timezone = strings.TrimSpace(*p.Timezone)
+	}
+
+	if p.WorkHours != nil {
+		h := *p.WorkHours
+		c.WorkHours = &h
+	}
+
+	if p.ExtendedHours != nil {
+		h := *p.ExtendedHours
+		c.ExtendedHours = &h
+	}
+
+	if p.WorkDays != nil {
+		c.WorkDays = append(Weekdays(nil), p.WorkDays...)
+	}
+
+	if len(p.AddTags) > 0 || len(p.RemoveTags) > 0 {
+		c.Tags = c.Tags.With(p.AddTags).Without(p.RemoveTags)
+	}
+
+	return c
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidTag = errors.New("invalid tag")
+
+const maxTags = 20
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,31}$`)
+
+// Tags labels a colleague with squads, roles or rotations. Tags are
+// lowercase, sorted and unique
+type Tags []string
+
+// ParseTags normalises tags given as separate values or comma separated
+// lists, e.g. "backend,oncall"
+func ParseTags(values ...string) (Tags, error) {
+	var tags Tags
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" {
+				continue
+			}
+			if !tagPattern.MatchString(tag) {
+				return nil, fmt.Errorf("%w: %q (use up to 32 letters, digits, '.', '-' or '_')", ErrInvalidTag, tag)
+			}
+			tags = append(tags, tag)
+		}
+	}
+	return tags.normalize(), nil
+}
+
+// Validate checks that every tag is well formed
+func (t Tags) Validate() error {
+	if len(t) > maxTags {
+		return fmt.Errorf("%w: too many tags (max %d)", ErrInvalidTag, maxTags)
+	}
+	for _, tag := range t {
+		if !tagPattern.MatchString(tag) {
+			return fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+		}
+	}
+	return nil
+}
+
+// Has reports whether tag is one of the tags
+func (t Tags) Has(tag string) bool {
+	return slices.Contains(t, tag)
+}
+
+// Match reports whether the tags include every tag in want, or at least one
+// of them when matchAny is set. Empty want matches everything
+func (t Tags) Match(want Tags, matchAny bool) bool {
+	if len(want) == 0 {
+		return true
+	}
+	for _, tag := range want {
+		has := t.Has(tag)
+		if matchAny && has {
+			return true
+		}
+		if !matchAny && !has {
+			return false
+		}
+	}
+	return !matchAny
+}
+
+// With returns the tags with others added
+func (t Tags) With(others Tags) Tags {
+	return append(slices.Clone(t), others...).normalize()
+}
+
+// Without returns the tags with others removed
+func (t Tags) Without(others Tags) Tags {
+	return slices.DeleteFunc(slices.Clone(t), others.Has).normalize()
+}
+
+func (t Tags) String() string {
+	return strings.Join(t, ",")
+}
+
+// normalize sorts the tags and drops duplicates, returning nil when empty so
+// that untagged colleagues are stored without a tags field
+func (t Tags) normalize() Tags {
+	if len(t) == 0 {
+		return nil
+	}
+	slices.Sort(t)
+	return slices.Compact(t)
+}
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Weekdays is a set of days of the week, stored in week order starting on Sunday
+type Weekdays []time.Weekday
+
+// Contains reports whether d is in the set
+func (w Weekdays) Contains(d time.Weekday) bool {
+	for _, day := range w {
+		if day == d {
+			return true
+		}
+	}
+	return false
+}
+
+func (w Weekdays) Validate() error {
+	seen := make(map[time.Weekday]bool, len(w))
+	for _, d := range w {
+		if d < time.Sunday || d > time.Saturday {
+			return fmt.Errorf("%w: unknown day %d", ErrInvalidWorkDays, d)
+		}
+		if seen[d] {
+			return fmt.Errorf("%w: %s listed more than once", ErrInvalidWorkDays, d)
+		}
+		seen[d] = true
+	}
+	return nil
+}
+
+func (w Weekdays) String() string {
+	names := make([]string, len(w))
+	for i, d := range w {
+		names[i] = weekdayName(d)
+	}
+	return strings.Join(names, ",")
+}
+
+func (w Weekdays) MarshalJSON() ([]byte, error) {
+	names := make([]string, len(w))
+	for i, d := range w {
+		names[i] = weekdayName(d)
+	}
+	return json.Marshal(names)
+}
+
+func (w *Weekdays) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+
+	days := make(Weekdays, 0, len(names))
+	for _, name := range names {
+		d, ok := weekdayNames[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("%w: unknown day %q", ErrInvalidWorkDays, name)
+		}
+		days = append(days, d)
+	}
+	*w = days
+	return nil
+}
+
+// ParseWeekdays parses a comma separated list of days and ranges, such as
+// "mon-fri", "sun-thu" or "mon,tue,thu". Ranges may wrap around the week
+func ParseWeekdays(s string) (Weekdays, error) {
+	var set [7]bool
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		start, ok := weekdayNames[strings.TrimSpace(from)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown day %q (use mon, tue, wed, thu, fri, sat, sun)", ErrInvalidWorkDays, from)
+		}
+
+		end := start
+		if isRange {
+			end, ok = weekdayNames[strings.TrimSpace(to)]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown day %q (use mon, tue, wed, thu, fri, sat, sun)", ErrInvalidWorkDays, to)
+			}
+		}
+
+		for d := start; ; d = (d + 1) % 7 {
+			set[d] = true
+			if d == end {
+				break
+			}
+		}
+	}
+
+	var days Weekdays
+	for d, ok := range set {
+		if ok {
+			days = append(days, time.Weekday(d))
+		}
+	}
+
+	if len(days) == 0 {
+		return nil, fmt.Errorf("%w: %q (expected format mon-fri)", ErrInvalidWorkDays, s)
+	}
+	return days, nil
+}
+
+func weekdayName(d time.Weekday) string {
+	return strings.ToLower(d.String()[:3])
+}
@@ -0,0 +1,16 @@
+package yaml
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Quote quotes s as a YAML double-quoted scalar. JSON string escaping is a
+// subset of YAML's, so the JSON encoding of a string is valid YAML
+func Quote(s string) string {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
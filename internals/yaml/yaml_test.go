@@ -0,0 +1,22 @@
+package yaml
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"Priya":       `"Priya"`,
+		"Lúcio":       `"Lúcio"`,
+		"yes":         `"yes"`,
+		"a: b # c":    `"a: b # c"`,
+		`say "hi"`:    `"say \"hi\""`,
+		"<R&D>":       `"<R&D>"`,
+		"line\nbreak": `"line\nbreak"`,
+		"back\\slash": `"back\\slash"`,
+	}
+
+	for in, want := range tests {
+		if got := Quote(in); got != want {
+			t.Errorf("Quote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}